@@ -17,20 +17,24 @@ package api
 
 import (
 	"bufio"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/pkg/errors"
-	"github.com/schollz/progressbar/v3"
 	"github.com/veeva/vvfst/config"
 	"github.com/veeva/vvfst/model"
 	"github.com/veeva/vvfst/net"
 	"github.com/veeva/vvfst/util"
 	"github.com/veeva/vvfst/vlog"
 	"io"
-	"math"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -39,6 +43,7 @@ var (
 		".": true,
 		"":  true,
 	}
+	remoteDirMutex = &sync.Mutex{}
 )
 
 // Login with username, password with configured in the config
@@ -54,11 +59,11 @@ func Login() error {
 		Post("/auth")
 
 	if err != nil {
-		return errors.Errorf("Failed to connect: %v", err)
+		return errors.Wrap(err, "Failed to connect")
 	}
 
 	if len(authResult.Errors) != 0 {
-		return errors.New(net.FormatRestResultError("", authResult.Errors[0]))
+		return net.NewRestError("", authResult.Errors[0])
 	}
 
 	net.LogTime("Login successful.", resp)
@@ -85,7 +90,7 @@ func ListPage(itemPath, nextPageURL string, limit int64, recursiveOpt, logStatus
 	}
 
 	if err != nil {
-		return nil, errors.Errorf("Failed to connect: %v", err)
+		return nil, errors.Wrap(err, "Failed to connect")
 	}
 
 	if itemsRestResult == nil {
@@ -97,12 +102,108 @@ func ListPage(itemPath, nextPageURL string, limit int64, recursiveOpt, logStatus
 	}
 
 	if len(itemsRestResult.Errors) != 0 {
-		return nil, errors.New(net.FormatRestResultError("", itemsRestResult.Errors[0]))
+		return nil, net.NewRestError("", itemsRestResult.Errors[0])
 	}
 
 	return itemsRestResult, nil
 }
 
+// WalkItems - call visit for every item under the path, following the pages of the listing.  The next page is
+// requested once every item of the page is visited, so a visitor which blocks slows down the listing.  Stops at
+// the first error returned by visit.  With a cache ttl a complete listing is saved on disk and visited from
+// there until it expires.
+func WalkItems(itemPath string, limit int64, recursiveOpt bool, visit func(item *model.Item) error) error {
+	if items, ok := readListing(itemPath, recursiveOpt); ok {
+		for _, item := range items {
+			if err := visit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	caching := config.CacheTTL() > 0
+	var listed []*model.Item
+	nextPageURL := ""
+	for {
+		itemsRestResult, err := ListPage(itemPath, nextPageURL, limit, recursiveOpt, false)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range itemsRestResult.Data {
+			if caching {
+				listed = append(listed, item)
+			}
+			if err := visit(item); err != nil {
+				return err
+			}
+		}
+
+		if itemsRestResult.ResponseDetails == nil || itemsRestResult.ResponseDetails.NextPage == "" {
+			if caching {
+				saveListing(itemPath, recursiveOpt, listed)
+			}
+			return nil
+		}
+		nextPageURL = itemsRestResult.ResponseDetails.NextPage
+	}
+}
+
+// ErrItemNotFound - the remote path does not exist
+var ErrItemNotFound = errors.New("not found")
+
+// StatItem - metadata of the file or folder at the remote path.  Listing a file returns the file
+// itself while listing a folder returns its content, a folder has only a path, name and kind.
+func StatItem(itemPath string) (*model.Item, error) {
+	if itemPath == "/" {
+		return &model.Item{Path: "/", Name: "/", Kind: "folder"}, nil
+	}
+
+	itemsRestResult, err := ListPage(itemPath, "", 1, false, false)
+	if net.ErrorType(err) == net.ErrorTypeInvalidData {
+		// the error type does not tell a missing path from another invalid request, the parent listing does
+		if missing, parentErr := missingItem(itemPath); parentErr == nil && missing {
+			return nil, errors.Wrap(ErrItemNotFound, itemPath)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(itemsRestResult.Data) == 1 && itemsRestResult.Data[0].Path == itemPath &&
+		itemsRestResult.Data[0].Kind != "folder" {
+		return itemsRestResult.Data[0], nil
+	}
+	return &model.Item{Path: itemPath, Name: path.Base(itemPath), Kind: "folder"}, nil
+}
+
+// missingItem - true when the parent folder does not list the item or does not exist itself
+func missingItem(itemPath string) (bool, error) {
+	parent := path.Dir(itemPath)
+	nextPageURL := ""
+	for {
+		itemsRestResult, err := ListPage(parent, nextPageURL, 1000, false, false)
+		if net.ErrorType(err) == net.ErrorTypeInvalidData && parent != "/" {
+			return missingItem(parent)
+		}
+		if err != nil {
+			return false, err
+		}
+
+		for _, item := range itemsRestResult.Data {
+			if item.Path == itemPath {
+				return false, nil
+			}
+		}
+		if itemsRestResult.ResponseDetails == nil || itemsRestResult.ResponseDetails.NextPage == "" {
+			return true, nil
+		}
+		nextPageURL = itemsRestResult.ResponseDetails.NextPage
+	}
+}
+
 // List items in the page, nextPageUrl is null then it will be the first page.
 func ListExport(itemPath string, recursiveOpt bool) (*model.JobRestResult, error) {
 	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
@@ -115,7 +216,7 @@ func ListExport(itemPath string, recursiveOpt bool) (*model.JobRestResult, error
 		Get(fmt.Sprintf("/services/file_staging/items%s", itemPath))
 
 	if err != nil {
-		return nil, errors.Errorf("Failed to connect: %v", err)
+		return nil, errors.Wrap(err, "Failed to connect")
 	}
 
 	if jobRestResult == nil {
@@ -123,16 +224,20 @@ func ListExport(itemPath string, recursiveOpt bool) (*model.JobRestResult, error
 	}
 
 	if len(jobRestResult.Errors) != 0 {
-		return nil, errors.New(net.FormatRestResultError("", jobRestResult.Errors[0]))
+		return nil, net.NewRestError("", jobRestResult.Errors[0])
 	}
 
 	return jobRestResult, nil
 }
 
 // Make the directory and ignores, dot, empty space directory
-func CreateFolder(remotePath string, overwrite, logStatus bool) {
-	if _, ok := remoteDirCache[remotePath]; ok {
-		return
+func CreateFolder(remotePath string, overwrite, logStatus bool) *model.TransferResult {
+	result := newTransferResult("", remotePath)
+	remoteDirMutex.Lock()
+	_, ok := remoteDirCache[remotePath]
+	remoteDirMutex.Unlock()
+	if ok {
+		return finishTransfer(result, nil)
 	}
 
 	formData := map[string]string{
@@ -151,30 +256,222 @@ func CreateFolder(remotePath string, overwrite, logStatus bool) {
 		Post("/services/file_staging/items")
 
 	if err != nil {
-		vlog.Errorf("Failed to connect: %v", err)
-		return
+		return finishTransfer(result, errors.Wrap(err, "Failed to connect"))
 	}
 
 	if len(itemRestResult.Errors) != 0 {
-		if itemRestResult.Errors[0].Type == "ITEM_NAME_EXISTS" && !logStatus {
-			return
+		if itemRestResult.Errors[0].Type != "ITEM_NAME_EXISTS" || logStatus {
+			return finishTransfer(result, net.NewRestError(remotePath, itemRestResult.Errors[0]))
 		}
-		net.LogRestResultError(remotePath, itemRestResult.Errors[0])
-		return
-	}
-
-	if logStatus {
+	} else if logStatus {
 		net.LogTime(fmt.Sprintf("created folder: %s", remotePath), resp)
 	}
+	InvalidateListings()
+
+	remoteDirMutex.Lock()
+	remoteDirCache[remotePath] = true
+	remoteDirMutex.Unlock()
+	return finishTransfer(result, nil)
 }
 
-// Download single from the file staging area
-func DownloadSingleFile(downloadItem *model.DownloadItem) {
+// Download single from the file staging area, the content is written to a partial file next to
+// the local path which is renamed into place once complete.  A download of the same remote item
+// which was interrupted earlier resumes from the end of the partial file with a range request.
+// A large file is downloaded in concurrent byte ranges when the item allows more than one thread.
+// The content is verified against the size and MD5 of the remote item, a mismatched file is
+// discarded and downloaded again. The item may come from a cached listing, so on the first mismatch
+// the item is listed again and the content verified against the current size and MD5.
+func DownloadSingleFile(downloadItem *model.DownloadItem) *model.TransferResult {
 	vlog.Debugf("Download file: %s, size: %d ", downloadItem.RemotePath, downloadItem.Size)
+	result := newTransferResult(downloadItem.LocalPath, downloadItem.RemotePath)
+	result.Method = model.TransferSimple
+
+	localParentDir := filepath.Dir(downloadItem.LocalPath)
+	localParentStat, _ := os.Stat(localParentDir)
+	if localParentStat == nil {
+		err := os.MkdirAll(localParentDir, 0755)
+		if err != nil {
+			return finishTransfer(result, errors.Wrapf(err, "Failed to create directory: %s", localParentDir))
+		}
+	}
+	if localParentStat != nil && !localParentStat.IsDir() {
+		return finishTransfer(result, errors.Errorf("Cannot create directory, a same filename exists: %s", localParentDir))
+	}
+
+	segmentSize := downloadSegmentSize(downloadItem)
+	restated := false
+	for {
+		partial, err := openPartial(downloadItem, segmentSize)
+		if err != nil {
+			return finishTransfer(result, err)
+		}
+
+		if segmentSize > 0 {
+			result.Method = model.TransferRanged
+			result.Size, result.MD5, err = downloadSegments(downloadItem, partial)
+			if err == errRangeNotSupported {
+				vlog.Warnf("Range request is not supported, downloading %s in a single stream", downloadItem.RemotePath)
+				partial.discard()
+				segmentSize = 0
+				result.Method = model.TransferSimple
+				continue
+			}
+		} else {
+			result.Size, result.MD5, err = downloadContent(downloadItem, partial)
+		}
+		if err == nil {
+			result.Verification, err = verifyDownload(downloadItem, result.Size, result.MD5)
+			if result.Verification == model.VerifyMismatch && !restated {
+				restated = true
+				if restatItem(downloadItem) {
+					result.Verification, err = verifyDownload(downloadItem, result.Size, result.MD5)
+				}
+			}
+		}
+		if err == nil {
+			err = partial.complete(downloadItem.LocalPath, downloadItem.Backup)
+			if err == nil && downloadItem.ModifiedDate != nil {
+				SetModifiedTime(downloadItem.LocalPath, *downloadItem.ModifiedDate)
+			}
+			if err != nil && aborted() {
+				err = ErrDownloadCancelled
+			}
+			return finishTransfer(result, err)
+		}
+
+		var checksumErr *net.ChecksumError
+		if !errors.As(err, &checksumErr) || aborted() {
+			partial.abort()
+			if aborted() {
+				err = ErrDownloadCancelled
+			}
+			return finishTransfer(result, err)
+		}
+
+		partial.discard()
+		if result.Attempts >= config.DownloadAttempts {
+			return finishTransfer(result, err)
+		}
+		result.Attempts++
+		vlog.Warnf("%v, downloading again (attempt %d of %d)", err, result.Attempts, config.DownloadAttempts)
+	}
+}
+
+// List the remote item again and update the size, MD5 and modified date of the download item,
+// returns false when the item cannot be listed or has not changed
+func restatItem(downloadItem *model.DownloadItem) bool {
+	item, err := StatItem(downloadItem.RemotePath)
+	if err != nil {
+		vlog.Debugf("Failed to list %s again: %v", downloadItem.RemotePath, err)
+		return false
+	}
+	if item.Kind == "folder" || item.Size == downloadItem.Size && strings.EqualFold(item.MD5, downloadItem.MD5) {
+		return false
+	}
+
+	vlog.Infof("The listing of %s was out of date, size: %d, MD5: %s", downloadItem.RemotePath, item.Size, item.MD5)
+	downloadItem.Size = item.Size
+	downloadItem.MD5 = item.MD5
+	downloadItem.ModifiedDate = item.ModifiedDate
+	return true
+}
+
+// SetModifiedTime - set the modified time of the downloaded file to the modified date of the remote
+// item so a later incremental download can compare the file without hashing it
+func SetModifiedTime(localPath string, modifiedDate time.Time) {
+	if err := os.Chtimes(localPath, time.Now(), modifiedDate); err != nil {
+		vlog.Warnf("Failed to set modified time: %s, err: %v", localPath, err)
+	}
+}
+
+// Compare the downloaded content with the size and MD5 of the remote item, the content is unverified
+// when the remote MD5 is not known such as for a job report
+func verifyDownload(downloadItem *model.DownloadItem, size int64, md5sum string) (model.VerifyStatus, error) {
+	if downloadItem.Size >= 0 && size != downloadItem.Size {
+		return model.VerifyMismatch, &net.ChecksumError{Path: downloadItem.RemotePath,
+			Message: fmt.Sprintf("received %d of %d bytes", size, downloadItem.Size)}
+	}
+
+	if downloadItem.MD5 == "" {
+		return model.VerifySkipped, nil
+	}
+
+	if !strings.EqualFold(md5sum, downloadItem.MD5) {
+		return model.VerifyMismatch, &net.ChecksumError{Path: downloadItem.RemotePath,
+			Message: fmt.Sprintf("MD5 %s does not match file_content_md5 %s", md5sum, downloadItem.MD5)}
+	}
+	return model.VerifyPassed, nil
+}
+
+// Download the content after the end of the partial file, returns the size and MD5 of the partial file
+func downloadContent(downloadItem *model.DownloadItem, partial *partialFile) (int64, string, error) {
+	digest := md5.New()
+	if err := partial.hashContent(digest); err != nil {
+		return 0, "", err
+	}
+	if partial.offset > 0 && partial.offset == downloadItem.Size {
+		return partial.offset, hex.EncodeToString(digest.Sum(nil)), nil
+	}
+
+	defer acquireConnection()()
+	resp, err := requestContent(downloadItem, partial.offset, -1)
+	if err != nil {
+		return partial.offset, "", err
+	}
+
+	// the partial file no longer fits the remote item, download it again from the start
+	if resp.StatusCode() == http.StatusRequestedRangeNotSatisfiable {
+		_ = resp.RawBody().Close()
+		if err := partial.restart(); err != nil {
+			return 0, "", err
+		}
+		digest.Reset()
+		if resp, err = requestContent(downloadItem, 0, -1); err != nil {
+			return 0, "", err
+		}
+	}
+	defer func() {
+		err := resp.RawBody().Close()
+		if err != nil {
+			vlog.Errorf("Error closing http response")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode() >= http.StatusBadRequest:
+		return partial.offset, "", errors.Errorf("Failed to download file: %s, status: %s", downloadItem.RemotePath, resp.Status())
+	case resp.StatusCode() != http.StatusPartialContent && partial.offset > 0:
+		vlog.Warnf("Range request is not supported, downloading %s from the start", downloadItem.RemotePath)
+		if err := partial.restart(); err != nil {
+			return 0, "", err
+		}
+		digest.Reset()
+	}
+
+	bar := buildProgressbar(filepath.Base(downloadItem.LocalPath), downloadItem.Size)
+	defer bar.done()
+	_ = bar.Add64(partial.offset)
+	n, err := io.Copy(io.MultiWriter(partial.file, digest, bar), resp.RawBody())
+	size := partial.offset + n
+	if err != nil {
+		return size, "", errors.Wrapf(err, "Failed to download file: %s", downloadItem.RemotePath)
+	}
+	return size, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Request the content of the remote item from start to end inclusive, a negative end requests
+// the content up to the end of the item
+func requestContent(downloadItem *model.DownloadItem, start, end int64) (*resty.Response, error) {
 	req := net.InitRestClient(config.EnableDebug).
 		BuildRestRequest(true).
 		SetDoNotParseResponse(true)
 
+	if end >= 0 {
+		req.SetHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	} else if start > 0 {
+		req.SetHeader("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+
 	var err error
 	var resp *resty.Response
 	if downloadItem.RemoteHref != "" {
@@ -184,60 +481,134 @@ func DownloadSingleFile(downloadItem *model.DownloadItem) {
 	}
 
 	if err != nil {
-		vlog.Errorf("Failed to download file: %s, error: %v", downloadItem.RemotePath, err)
-		return
+		return nil, errors.Wrapf(err, "Failed to download file: %s", downloadItem.RemotePath)
 	}
-	defer func() {
-		err = resp.RawBody().Close()
-		if err != nil {
-			vlog.Errorf("Error closing http response")
-		}
-	}()
+	return resp, nil
+}
 
-	localParentDir := filepath.Dir(downloadItem.LocalPath)
-	localParentStat, err := os.Stat(localParentDir)
-	if localParentStat == nil {
-		err := os.MkdirAll(localParentDir, 0755)
-		if err != nil {
-			vlog.Errorf("Failed to create directory: %s, err: %v", localParentDir, err)
-			return
-		}
+// DeleteItem - delete the remote file or folder and wait for the deletion job, a folder with content is
+// deleted only when recursive
+func DeleteItem(remotePath string, recursive bool) error {
+	var jobRestResult *model.JobRestResult
+	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
+	resp, err := req.SetResult(&jobRestResult).
+		SetQueryParam("recursive", strconv.FormatBool(recursive)).
+		Delete(fmt.Sprintf("/services/file_staging/items%s", remotePath))
+
+	if err != nil {
+		return errors.Wrap(err, "Failed to connect")
 	}
-	if localParentStat != nil && !localParentStat.IsDir() {
-		vlog.Errorf("Cannot create directory, a same filename exists: %s", localParentDir)
-		return
+
+	if jobRestResult == nil {
+		return errors.Errorf("Unknown error, response is empty")
 	}
 
-	f, _ := os.OpenFile(downloadItem.LocalPath, os.O_CREATE|os.O_WRONLY, 0644)
-	bar := buildProgressbar(filepath.Base(downloadItem.LocalPath), downloadItem.Size)
-	_, err = io.Copy(io.MultiWriter(f, bar), resp.RawBody())
+	if len(jobRestResult.Errors) != 0 {
+		return net.NewRestError("", jobRestResult.Errors[0])
+	}
+
+	net.LogTime("rm submitted successfully, waiting for job completion", resp)
+	return waitForStagingJob(jobRestResult.Data.JobID, fmt.Sprintf("%s removed successfully", remotePath))
+}
+
+// MoveItem - move or rename the remote file or folder into the parent folder under the name and wait for
+// the move job
+func MoveItem(remotePath, destParent, destName string, overwriteOpt bool) error {
+	params := map[string]string{
+		"parent":    destParent,
+		"name":      destName,
+		"overwrite": strconv.FormatBool(overwriteOpt),
+	}
+
+	var jobRestResult *model.JobRestResult
+	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
+	resp, err := req.SetResult(&jobRestResult).
+		SetHeader("Content-Type", "application/x-www-form-urlencoded").
+		SetFormData(params).
+		Put(fmt.Sprintf("/services/file_staging/items%s", remotePath))
 
 	if err != nil {
-		vlog.Errorf("Failed to download file: %s, err: %v", downloadItem.RemotePath, err)
-		return
+		return errors.Wrap(err, "Failed to connect")
+	}
+
+	if jobRestResult == nil {
+		return errors.Errorf("Unknown error, response is empty")
 	}
+
+	if len(jobRestResult.Errors) != 0 {
+		return net.NewRestError("", jobRestResult.Errors[0])
+	}
+
+	net.LogTime("mv submitted successfully, waiting for job completion", resp)
+	return waitForStagingJob(jobRestResult.Data.JobID,
+		fmt.Sprintf("%s moved to %s successfully", remotePath, path.Join(destParent, destName)))
 }
 
-//UploadSingleFile - uploads single file using if size is less than 50MB
-func UploadSingleFile(uploadItem *model.UploadItem, overwriteOpt bool) {
+//UploadSingleFile - uploads single file, files larger than the multipart threshold are uploaded in parts
+func UploadSingleFile(uploadItem *model.UploadItem, overwriteOpt bool) *model.TransferResult {
+	result := newTransferResult(uploadItem.LocalPath, uploadItem.RemotePath)
 	fi, err := os.Stat(uploadItem.LocalPath)
 	if err != nil {
-		vlog.Errorf("%s file not found, err: %v", uploadItem.LocalPath, err)
-		return
+		return finishTransfer(result, errors.Wrapf(err, "%s file not found", uploadItem.LocalPath))
 	}
+	result.Size = fi.Size()
 
-	if fi.Size() > config.Size50MB {
-		err := MultipartUploadSingleFile(uploadItem.LocalPath, uploadItem.RemotePath, overwriteOpt)
-		if err != nil {
-			vlog.Errorf("%v", err)
+	if fi.Size() > config.MultipartThreshold() {
+		result.Method = model.TransferMultipart
+		var session *model.UploadSession
+		session, result.MD5, err = multipartUploadFile(uploadItem.LocalPath, uploadItem.RemotePath, overwriteOpt)
+		if session != nil {
+			result.Attempts += session.PartRetries
 		}
-		return
+		return finishTransfer(result, err)
+	}
+
+	result.Method = model.TransferSimple
+	file, err := os.Open(uploadItem.LocalPath)
+	if err != nil {
+		return finishTransfer(result, errors.Wrapf(err, "Failed to open file: %s", uploadItem.LocalPath))
+	}
+	defer file.Close()
+
+	digest := md5.New()
+	err = uploadContent(uploadItem.RemotePath, fi.Size(), io.TeeReader(file, digest), overwriteOpt)
+	if err == nil {
+		result.MD5 = hex.EncodeToString(digest.Sum(nil))
+	}
+	return finishTransfer(result, err)
+}
+
+func newTransferResult(localPath, remotePath string) *model.TransferResult {
+	return &model.TransferResult{
+		LocalPath:  localPath,
+		RemotePath: remotePath,
+		Attempts:   1,
+		StartTime:  time.Now(),
+	}
+}
+
+// Complete the result with the duration and final status, the failure is left to the caller to report
+func finishTransfer(result *model.TransferResult, err error) *model.TransferResult {
+	result.Duration = time.Since(result.StartTime)
+	if err != nil {
+		result.Status = model.TransferFailed
+		result.ErrorType = net.ErrorType(err)
+		result.Err = err
+		return result
 	}
 
+	result.Status = model.TransferSucceeded
+	return result
+}
+
+// Upload the content as a single file with one request, the content is copied as is since
+// SetFileReader fails on a reader which returns EOF with the first read such as an empty file
+func uploadContent(remotePath string, size int64, content io.Reader, overwriteOpt bool) error {
+	name := util.GetFilename(remotePath)
 	formData := map[string]string{
-		"path":      uploadItem.RemotePath,
-		"name":      util.GetFilename(uploadItem.RemotePath),
-		"size":      strconv.FormatInt(fi.Size(), 10),
+		"path":      remotePath,
+		"name":      name,
+		"size":      strconv.FormatInt(size, 10),
 		"kind":      "file",
 		"overwrite": strconv.FormatBool(overwriteOpt),
 	}
@@ -248,20 +619,20 @@ func UploadSingleFile(uploadItem *model.UploadItem, overwriteOpt bool) {
 	resp, err := req.
 		SetResult(&itemRestResult).
 		SetMultipartFormData(formData).
-		SetFile("file", uploadItem.LocalPath).
+		SetMultipartField("file", name, "application/octet-stream", content).
 		Post("/services/file_staging/items")
 
 	if err != nil {
-		vlog.Errorf("Failed to connect: %v", err)
-		return
+		return errors.Wrap(err, "Failed to connect")
 	}
 
 	if len(itemRestResult.Errors) != 0 {
-		net.LogRestResultError(uploadItem.RemotePath, itemRestResult.Errors[0])
-		return
+		return net.NewRestError(remotePath, itemRestResult.Errors[0])
 	}
 
-	net.LogTime(fmt.Sprintf("uploaded file: %s", uploadItem.RemotePath), resp)
+	InvalidateListings()
+	net.LogTime(fmt.Sprintf("uploaded file: %s", remotePath), resp)
+	return nil
 }
 
 //MultipartList - list all active multipart session
@@ -275,7 +646,7 @@ func MultipartList(logStatus bool) (*model.UploadSessionsRestResult, error) {
 		Get("/services/file_staging/upload")
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect: %v", err)
+		return nil, errors.Wrap(err, "failed to connect")
 	}
 
 	if logStatus {
@@ -283,51 +654,128 @@ func MultipartList(logStatus bool) (*model.UploadSessionsRestResult, error) {
 	}
 
 	if len(sessionsRestResult.Errors) != 0 {
-		return nil, errors.New(net.FormatRestResultError("", sessionsRestResult.Errors[0]))
+		return nil, net.NewRestError("", sessionsRestResult.Errors[0])
 	}
 	return sessionsRestResult, nil
 }
 
-//MultipartUploadSingleFile - Upload single file using multipart
-func MultipartUploadSingleFile(localPath, remotePath string, overwriteOpt bool) error {
+//MultipartUploadSingleFile - Upload single file using multipart, returns MD5 of the content.
+//The session is tracked per remote file in the journal so an interrupted upload resumes the same
+//session when the local file did not change.
+func MultipartUploadSingleFile(localPath, remotePath string, overwriteOpt bool) (string, error) {
+	_, md5sum, err := multipartUploadFile(localPath, remotePath, overwriteOpt)
+	return md5sum, err
+}
+
+// Upload single file using multipart, returns the session, which counts the retried parts, once it is
+// created and the MD5 of the content
+func multipartUploadFile(localPath, remotePath string, overwriteOpt bool) (*model.UploadSession, string, error) {
 	fi, err := os.Stat(localPath)
 	if err != nil {
-		return errors.Errorf("%s file not found", localPath)
+		return nil, "", errors.Wrapf(err, "%s file not found", localPath)
 	}
 
 	if fi.Size() < config.Size5MB {
-		return errors.Errorf("%s file is less than %d", localPath, config.Size5MB)
+		return nil, "", errors.Errorf("%s file is less than %d", localPath, config.Size5MB)
+	}
+
+	uploadSession, err := resumeUploadSession(localPath, remotePath, fi)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if uploadSession == nil {
+		uploadSession, err = MultipartUploadBegin(localPath, remotePath, overwriteOpt)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	entry := &model.UploadJournalEntry{
+		RemotePath:   remotePath,
+		SessionID:    uploadSession.UploadSessionID,
+		LocalPath:    localPath,
+		Size:         fi.Size(),
+		ModTime:      fi.ModTime().UnixNano(),
+		PartsDone:    uploadSession.UploadedPartsCount,
+		UploadedSize: uploadSession.UploadedSize,
+	}
+	config.SaveUploadSession(entry)
+
+	digest := md5.New()
+	err = MultipartUploadFilePart(localPath, uploadSession, entry, digest)
+	if err != nil {
+		return uploadSession, "", err
+	}
+
+	err = MultipartUploadCommit(uploadSession)
+	if err != nil {
+		return uploadSession, "", err
+	}
+
+	config.RemoveUploadSession(remotePath)
+	return uploadSession, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Find the session of the remote file in the journal, a session is resumed only when it is still
+// active and the local file has the same fingerprint, otherwise the stale session is discarded
+func resumeUploadSession(localPath, remotePath string, fi os.FileInfo) (*model.UploadSession, error) {
+	entry := config.UploadSession(remotePath)
+	if entry == nil {
+		return nil, nil
 	}
 
 	sessionsRestResult, err := MultipartList(false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var uploadSession *model.UploadSession
 	for _, item := range sessionsRestResult.Data {
-		if item.Path == remotePath {
+		if item.UploadSessionID == entry.SessionID {
 			uploadSession = item
 			break
 		}
 	}
 
 	if uploadSession == nil {
-		uploadSession, err = MultipartUploadBegin(localPath, remotePath, overwriteOpt)
-		if err != nil {
-			return err
+		vlog.Infof("Upload session %s for %s is no longer active, starting a new session", entry.SessionID, remotePath)
+		config.RemoveUploadSession(remotePath)
+		return nil, nil
+	}
+
+	if entry.LocalPath != localPath || entry.Size != fi.Size() || entry.ModTime != fi.ModTime().UnixNano() {
+		vlog.Infof("%s changed since upload session %s began, starting a new session", localPath, entry.SessionID)
+		if err := MultipartUploadDelete(uploadSession); err != nil {
+			vlog.Warnf("Failed to delete stale upload session %s: %v", entry.SessionID, err)
 		}
+		config.RemoveUploadSession(remotePath)
+		return nil, nil
 	}
 
-	config.SetUploadSessionID(uploadSession.UploadSessionID)
-	config.UpdateConfig()
+	vlog.Infof("Resuming upload session %s for %s, %d part(s) already uploaded", entry.SessionID, remotePath, uploadSession.UploadedPartsCount)
+	return uploadSession, nil
+}
+
+//MultipartUploadDelete - Delete the multipart upload session
+func MultipartUploadDelete(uploadSession *model.UploadSession) error {
+	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
+	var restResult model.RestResult
+	resp, err := req.
+		SetResult(&restResult).
+		Delete(fmt.Sprintf("/services/file_staging/upload/%s", uploadSession.UploadSessionID))
 
-	err = MultipartUploadFilePart(localPath, uploadSession)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "Failed to connect")
 	}
 
-	return MultipartUploadCommit(uploadSession)
+	if len(restResult.Errors) != 0 {
+		return net.NewRestError(uploadSession.Path, restResult.Errors[0])
+	}
+
+	config.RemoveUploadSession(uploadSession.Path)
+	net.LogTime(fmt.Sprintf("Deleted upload session for %s", uploadSession.Path), resp)
+	return nil
 }
 
 //MultipartUploadBegin - Begin multipart upload session
@@ -337,10 +785,15 @@ func MultipartUploadBegin(localPath, remotePath string, overwriteOpt bool) (*mod
 		return nil, errors.Errorf("%s file not found", localPath)
 	}
 
+	return multipartUploadBegin(remotePath, fi.Size(), overwriteOpt)
+}
+
+// Begin multipart upload session of a file of the given size
+func multipartUploadBegin(remotePath string, size int64, overwriteOpt bool) (*model.UploadSession, error) {
 	formData := map[string]string{
 		"path":      remotePath,
 		"name":      filepath.Base(remotePath),
-		"size":      strconv.FormatInt(fi.Size(), 10),
+		"size":      strconv.FormatInt(size, 10),
 		"overwrite": strconv.FormatBool(overwriteOpt),
 	}
 
@@ -353,11 +806,11 @@ func MultipartUploadBegin(localPath, remotePath string, overwriteOpt bool) (*mod
 		Post("/services/file_staging/upload")
 
 	if err != nil {
-		return nil, errors.Errorf("Failed to connect: %v", err)
+		return nil, errors.Wrap(err, "Failed to connect")
 	}
 
 	if len(sessionRestResult.Errors) != 0 {
-		return nil, errors.Errorf(net.FormatRestResultError(remotePath, sessionRestResult.Errors[0]))
+		return nil, net.NewRestError(remotePath, sessionRestResult.Errors[0])
 	}
 
 	net.LogTime(fmt.Sprintf("upload session created for file: %s", remotePath), resp)
@@ -366,76 +819,51 @@ func MultipartUploadBegin(localPath, remotePath string, overwriteOpt bool) (*mod
 	return uploadSession, nil
 }
 
-//MultipartUploadFilePart - Upload multipart file
-func MultipartUploadFilePart(localPath string, session *model.UploadSession) error {
-	uploadSessionID := config.UploadSessionID()
-
-	if uploadSessionID == "" {
-		return errors.Errorf("Upload session not found for filepath: %s", localPath)
-	}
-
-	size, chunkSize, err := findChunkSize(localPath)
+//MultipartUploadFilePart - Upload remaining parts of the file to the session, the whole content is also
+//written to the digest and the progress is recorded in the journal entry
+func MultipartUploadFilePart(localPath string, session *model.UploadSession, entry *model.UploadJournalEntry, digest io.Writer) error {
+	fi, err := os.Stat(localPath)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "%s file not found", localPath)
 	}
 
 	file, err := os.Open(localPath)
 	if err != nil {
-		return errors.Errorf("Failed to open file: %s", localPath)
+		return errors.Wrapf(err, "Failed to open file: %s", localPath)
 	}
 	defer file.Close()
 	reader := bufio.NewReader(file)
-	buffer := make([]byte, chunkSize)
-	remainingSize := size - session.UploadedSize
-	partNumber := 1
-	totalParts := int(math.Ceil(float64(size) / float64(chunkSize)))
 
-	if session.UploadedPartsCount > 0 {
-		partNumber = session.UploadedPartsCount + 1
+	// parts uploaded in a previous run are read for the digest only
+	if _, err := io.CopyN(digest, reader, session.UploadedSize); err != nil {
+		return errors.Wrapf(err, "Failed to read file: %s", localPath)
 	}
 
-	for ; ; partNumber++ {
-		if remainingSize == 0 {
-			return nil
-		}
-
-		if remainingSize < chunkSize {
-			buffer = make([]byte, remainingSize)
-		}
-
-		n, err := reader.Read(buffer)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return errors.Errorf("cannot read chunk to buffer, err: %v", err)
-		}
-
-		var partRestResult model.UploadPartRestResult
-		req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
-		_, err = req.
-			SetResult(&partRestResult).
-			SetHeader("X-VaultAPI-FilePartNumber", strconv.FormatInt(int64(partNumber), 10)).
-			SetHeader("Content-Length", strconv.FormatInt(int64(n), 10)).
-			SetHeader("Content-Type", "application/octet-stream").
-			SetBody(buffer).
-			Put(fmt.Sprintf("/services/file_staging/upload/%s", uploadSessionID))
-
-		if err != nil {
-			return errors.Errorf("Failed to upload file part: %s", localPath)
-		}
+	_, err = uploadParts(session, reader, fi.Size(), entry, digest)
+	return err
+}
 
-		if len(partRestResult.Errors) != 0 {
-			return errors.Errorf(net.FormatRestResultError(session.Path, partRestResult.Errors[0]))
-		}
+// Upload a single part of the multipart session
+func uploadPart(uploadSessionID, remotePath string, partNumber int, content []byte) (*model.UploadPart, error) {
+	var partRestResult model.UploadPartRestResult
+	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
+	_, err := req.
+		SetResult(&partRestResult).
+		SetHeader("X-VaultAPI-FilePartNumber", strconv.FormatInt(int64(partNumber), 10)).
+		SetHeader("Content-Length", strconv.FormatInt(int64(len(content)), 10)).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(content).
+		Put(fmt.Sprintf("/services/file_staging/upload/%s", uploadSessionID))
 
-		remainingSize = remainingSize - int64(n)
+	if err != nil {
+		return nil, errors.Errorf("Failed to upload file part %d: %s, err: %v", partNumber, remotePath, err)
+	}
 
-		vlog.Infof("[%s] Uploaded part: %d of %d, size: %s, partContentMD5: %s", session.Path,
-			partRestResult.Data.PartNumber, totalParts, util.ByteCountSI(partRestResult.Data.PartSize), partRestResult.Data.PartContentMD5)
+	if len(partRestResult.Errors) != 0 {
+		return nil, net.NewRestError(remotePath, partRestResult.Errors[0])
 	}
 
-	return nil
+	return partRestResult.Data, nil
 }
 
 // Commit the Multipart session
@@ -448,17 +876,26 @@ func MultipartUploadCommit(uploadSession *model.UploadSession) error {
 		Post(fmt.Sprintf("/services/file_staging/upload/%s", uploadSession.UploadSessionID))
 
 	if err != nil {
-		return errors.Errorf("Failed to connect: %v", err)
+		return errors.Wrap(err, "Failed to connect")
 	}
 
 	if len(jobRestResult.Errors) != 0 {
-		return errors.Errorf(net.FormatRestResultError(uploadSession.Path, jobRestResult.Errors[0]))
+		return net.NewRestError(uploadSession.Path, jobRestResult.Errors[0])
 	}
 
 	net.LogTime(fmt.Sprintf("upload session completed for file: %s, waiting for job completion", uploadSession.Path), resp)
 	msg := fmt.Sprintf("%s file upload sucessfully", uploadSession.Path)
+	return waitForStagingJob(jobRestResult.Data.JobID, msg)
+}
+
+// Wait for a job changing the staging area such as a move, a deletion or an upload commit.  The cached
+// listings are cleared before the job and again once it is done, as a listing made while the job runs
+// may still see the old content.
+func waitForStagingJob(jobID int64, message string) error {
+	InvalidateListings()
+	defer InvalidateListings()
 
-	_, err = WaitForJobCompletion(jobRestResult.Data.JobID, msg, config.JobTimeoutSeconds)
+	_, err := WaitForJobCompletion(jobID, message, config.JobTimeoutSeconds)
 	return err
 }
 
@@ -478,11 +915,11 @@ func WaitForJobCompletion(jobID int64, message string, timeoutSec int) (*model.L
 			Get(fmt.Sprintf("/services/jobs/%d", jobID))
 
 		if err != nil {
-			return nil, errors.Errorf("Failed to connect: %v", err)
+			return nil, errors.Wrap(err, "Failed to connect")
 		}
 
 		if len(jobStatusRestResult.Errors) != 0 {
-			return nil, errors.Errorf(net.FormatRestResultError(jobIDStr, jobStatusRestResult.Errors[0]))
+			return nil, net.NewRestError(jobIDStr, jobStatusRestResult.Errors[0])
 		}
 
 		if jobStatusRestResult.Data.Status == "SUCCESS" {
@@ -510,44 +947,3 @@ func WaitForJobCompletion(jobID int64, message string, timeoutSec int) (*model.L
 
 	return nil, errors.Errorf("Job not completed within %d seconds", timeoutSec)
 }
-
-func buildProgressbar(name string, size int64) *progressbar.ProgressBar {
-	bar := progressbar.NewOptions64(
-		size,
-		progressbar.OptionSetDescription(fmt.Sprintf("downloading %s ", name)),
-		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionSetWidth(10),
-		progressbar.OptionThrottle(65*time.Millisecond),
-		progressbar.OptionShowCount(),
-		progressbar.OptionShowIts(),
-		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionOnCompletion(func() {
-			_, _ = fmt.Fprint(os.Stderr, "\n")
-		}),
-		progressbar.OptionSpinnerType(14),
-		progressbar.OptionSetTheme(progressbar.Theme{Saucer: "=", SaucerPadding: " ", BarStart: ">", BarEnd: "|"}),
-		progressbar.OptionFullWidth(),
-	)
-	_ = bar.RenderBlank()
-	return bar
-}
-
-func findChunkSize(localPath string) (int64, int64, error) {
-	fi, err := os.Stat(localPath)
-	if err != nil {
-		return 0, 0, errors.Errorf("%s file not found", localPath)
-	}
-
-	var MB int64 = 1024 * 1024
-	var GB = MB * 1024
-
-	if fi.Size() < 5*GB {
-		return fi.Size(), 5 * MB, nil
-	}
-
-	if fi.Size() < 100*GB {
-		return fi.Size(), 25 * MB, nil
-	}
-
-	return fi.Size(), 50 * MB, nil
-}
@@ -0,0 +1,130 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/vlog"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cachedListing - every item of a complete remote listing, saved on disk for the cache ttl
+type cachedListing struct {
+	Path      string        `json:"path"`
+	Recursive bool          `json:"recursive"`
+	Created   time.Time     `json:"created"`
+	Items     []*model.Item `json:"items"`
+}
+
+// listingCacheDir - the cached listings of the vault and user logged in, listings of another login
+// are never used
+func listingCacheDir() string {
+	sum := md5.Sum([]byte(config.DomainName() + "\n" + config.Username()))
+	return filepath.Join(config.CacheDir(), hex.EncodeToString(sum[:]))
+}
+
+func listingCachePath(itemPath string, recursiveOpt bool) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s\n%t", cacheItemPath(itemPath), recursiveOpt)))
+	return filepath.Join(listingCacheDir(), hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheItemPath - same listing for a folder with and without the trailing separator
+func cacheItemPath(itemPath string) string {
+	if itemPath == "" || itemPath == "/" {
+		return "/"
+	}
+	return strings.TrimSuffix(itemPath, "/")
+}
+
+// readListing - the cached items of the listing, false when the cache is disabled, refreshed, expired
+// or has no listing of the folder
+func readListing(itemPath string, recursiveOpt bool) ([]*model.Item, bool) {
+	ttl := config.CacheTTL()
+	if ttl == 0 || config.RefreshCache {
+		return nil, false
+	}
+
+	content, err := ioutil.ReadFile(listingCachePath(itemPath, recursiveOpt))
+	if err != nil {
+		return nil, false
+	}
+
+	var listing cachedListing
+	if err := json.Unmarshal(content, &listing); err != nil {
+		vlog.Debugf("Ignoring unreadable cached listing of %s: %v", itemPath, err)
+		return nil, false
+	}
+	if listing.Path != cacheItemPath(itemPath) || listing.Recursive != recursiveOpt || time.Since(listing.Created) > ttl {
+		return nil, false
+	}
+
+	vlog.Debugf("Using the listing of %s cached at %s", itemPath, listing.Created.Format(time.RFC3339))
+	return listing.Items, true
+}
+
+// saveListing - cache the items of a complete listing, a listing which cannot be saved is only logged
+func saveListing(itemPath string, recursiveOpt bool, items []*model.Item) {
+	if config.CacheTTL() == 0 {
+		return
+	}
+
+	listing := &cachedListing{Path: cacheItemPath(itemPath), Recursive: recursiveOpt, Created: time.Now(), Items: items}
+	content, err := json.Marshal(listing)
+	if err != nil {
+		vlog.Debugf("Failed to cache the listing of %s: %v", itemPath, err)
+		return
+	}
+
+	// written next to the cached listing and renamed so a concurrent command never reads half of it
+	cachePath := listingCachePath(itemPath, recursiveOpt)
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		vlog.Debugf("Failed to cache the listing of %s: %v", itemPath, err)
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(cachePath), ".listing-")
+	if err != nil {
+		vlog.Debugf("Failed to cache the listing of %s: %v", itemPath, err)
+		return
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), cachePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		vlog.Debugf("Failed to cache the listing of %s: %v", itemPath, err)
+	}
+}
+
+// InvalidateListings - remove the cached listings of the vault after a change to the staging area,
+// a folder listing includes its sub folders so every cached listing may be affected
+func InvalidateListings() {
+	if err := os.RemoveAll(listingCacheDir()); err != nil {
+		vlog.Warnf("Failed to clear the cached listings: %v", err)
+	}
+}
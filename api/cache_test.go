@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// inCacheDir - run the test in a temporary folder, the cache folder is next to the configuration file
+// which is not set in a test
+func inCacheDir(t *testing.T, ttl string) func() {
+	dir, err := ioutil.TempDir("", "vvfst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	config.SetFlagValue(config.ConfigKeyCacheTTL, ttl)
+
+	return func() {
+		config.SetFlagValue(config.ConfigKeyCacheTTL, "")
+		config.RefreshCache = false
+		_ = os.Chdir(wd)
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestListingRoundTrip(t *testing.T) {
+	defer inCacheDir(t, "1h")()
+
+	items := []*model.Item{{Path: "/inbox/a.xml", Name: "a.xml", Kind: "file", Size: 3}}
+	saveListing("/inbox", false, items)
+
+	tests := []struct {
+		itemPath  string
+		recursive bool
+		refresh   bool
+		want      bool
+	}{
+		{"/inbox", false, false, true},
+		{"/inbox/", false, false, true},
+		{"/inbox", true, false, false},
+		{"/", false, false, false},
+		{"/inbox", false, true, false},
+	}
+
+	for _, test := range tests {
+		config.RefreshCache = test.refresh
+		got, ok := readListing(test.itemPath, test.recursive)
+		if ok != test.want {
+			t.Errorf("readListing(%q, %t) with refresh %t = %t, want %t", test.itemPath, test.recursive, test.refresh, ok, test.want)
+			continue
+		}
+		if ok && (len(got) != 1 || got[0].Path != items[0].Path || got[0].Size != items[0].Size) {
+			t.Errorf("readListing(%q, %t) = %+v, want %+v", test.itemPath, test.recursive, got, items)
+		}
+	}
+}
+
+func TestListingTTL(t *testing.T) {
+	tests := []struct {
+		ttl  string
+		age  time.Duration
+		want bool
+	}{
+		{"1h", time.Minute, true},
+		{"1h", 2 * time.Hour, false},
+		{"10m", 11 * time.Minute, false},
+		{"0", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		func() {
+			defer inCacheDir(t, test.ttl)()
+
+			saveListing("/inbox", true, []*model.Item{{Path: "/inbox/a.xml"}})
+			if config.CacheTTL() > 0 {
+				// age the saved listing
+				content, err := ioutil.ReadFile(listingCachePath("/inbox", true))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var listing cachedListing
+				if err := json.Unmarshal(content, &listing); err != nil {
+					t.Fatal(err)
+				}
+				listing.Created = time.Now().Add(-test.age)
+				content, _ = json.Marshal(listing)
+				if err := ioutil.WriteFile(listingCachePath("/inbox", true), content, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, ok := readListing("/inbox", true); ok != test.want {
+				t.Errorf("readListing() with ttl %q and age %v = %t, want %t", test.ttl, test.age, ok, test.want)
+			}
+		}()
+	}
+}
+
+func TestInvalidateListings(t *testing.T) {
+	defer inCacheDir(t, "1h")()
+
+	saveListing("/inbox", false, []*model.Item{{Path: "/inbox/a.xml"}})
+	saveListing("/", true, []*model.Item{{Path: "/inbox"}})
+	InvalidateListings()
+
+	tests := []struct {
+		itemPath  string
+		recursive bool
+	}{
+		{"/inbox", false},
+		{"/", true},
+	}
+	for _, test := range tests {
+		if _, ok := readListing(test.itemPath, test.recursive); ok {
+			t.Errorf("readListing(%q, %t) after InvalidateListings() = true, want false", test.itemPath, test.recursive)
+		}
+	}
+
+	// a listing saved after the invalidation is cached again
+	saveListing("/inbox", false, []*model.Item{{Path: "/inbox/a.xml"}})
+	if _, ok := readListing("/inbox", false); !ok {
+		t.Errorf("readListing(%q, false) after saveListing() = false, want true", "/inbox")
+	}
+}
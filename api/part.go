@@ -0,0 +1,228 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"github.com/pkg/errors"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/net"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"math"
+	"time"
+)
+
+// Part upload duration window of the auto part size, faster parts grow and slower parts shrink
+const (
+	autoPartFastDuration = 3 * time.Second
+	autoPartSlowDuration = 20 * time.Second
+	autoPartMaxErrorRate = 0.1
+)
+
+// partSizer - chooses the size of the next part, either fixed or tuned from the measured throughput and error rate
+type partSizer struct {
+	size     int64
+	auto     bool
+	attempts int
+	failures int
+}
+
+// newPartSizer - part size from the configuration for a file of the total size
+func newPartSizer(totalSize int64) (*partSizer, error) {
+	sizer := &partSizer{size: defaultPartSize(totalSize)}
+
+	switch partSize := config.PartSize(); partSize {
+	case "":
+	case config.PartSizeAuto:
+		sizer.auto = true
+	default:
+		size, err := util.ParseByteSize(partSize)
+		if err != nil {
+			return nil, err
+		}
+		sizer.size = size
+	}
+
+	if !sizer.auto {
+		if minSize := minPartSize(totalSize, 0); sizer.size < minSize {
+			return nil, errors.Errorf("part size %s exceeds the maximum of %d parts for %s, use at least %s",
+				util.ByteCountIEC(sizer.size), config.MaxPartCount, util.ByteCountIEC(totalSize), util.ByteCountIEC(minSize))
+		}
+	}
+	return sizer, nil
+}
+
+// defaultPartSize - part size chosen by the size of the file
+func defaultPartSize(totalSize int64) int64 {
+	var MB int64 = 1024 * 1024
+	var GB = MB * 1024
+
+	if totalSize < 5*GB {
+		return 5 * MB
+	}
+
+	if totalSize < 100*GB {
+		return 25 * MB
+	}
+
+	return 50 * MB
+}
+
+// minPartSize - smallest part size to upload the remaining bytes within the maximum part count
+func minPartSize(remaining int64, partsDone int) int64 {
+	partsLeft := config.MaxPartCount - partsDone
+	if partsLeft <= 0 {
+		return config.MaxPartSize
+	}
+	return int64(math.Ceil(float64(remaining) / float64(partsLeft)))
+}
+
+// next - size of the next part, large enough to upload the remaining bytes within the maximum part count
+func (p *partSizer) next(remaining int64, partsDone int) int64 {
+	size := p.size
+	if remaining > 0 {
+		if minSize := minPartSize(remaining, partsDone); size < minSize {
+			size = minSize
+		}
+	}
+
+	if size < config.MinPartSize {
+		size = config.MinPartSize
+	}
+	if size > config.MaxPartSize {
+		size = config.MaxPartSize
+	}
+	p.size = size
+	return size
+}
+
+// succeeded - record the upload time of the part, auto mode grows the part size on a fast link and
+// shrinks it on a slow link
+func (p *partSizer) succeeded(n int64, d time.Duration) {
+	p.attempts++
+	if !p.auto || n < p.size {
+		return
+	}
+
+	errorRate := float64(p.failures) / float64(p.attempts)
+	switch {
+	case d < autoPartFastDuration && errorRate <= autoPartMaxErrorRate && p.size < config.MaxPartSize:
+		p.size *= 2
+		vlog.Debugf("Part uploaded in %v (%s/s), increasing part size to %s", d, util.ByteCountSI(int64(float64(n)/d.Seconds())), util.ByteCountSI(p.size))
+	case d > autoPartSlowDuration && p.size > config.MinPartSize:
+		p.size /= 2
+		vlog.Debugf("Part uploaded in %v (%s/s), decreasing part size to %s", d, util.ByteCountSI(int64(float64(n)/d.Seconds())), util.ByteCountSI(p.size))
+	}
+}
+
+// failed - record a failed part, auto mode shrinks the part size so a retry is cheaper
+func (p *partSizer) failed() {
+	p.attempts++
+	p.failures++
+	if p.auto && p.size > config.MinPartSize {
+		p.size /= 2
+		vlog.Debugf("Part upload failed, decreasing part size to %s", util.ByteCountSI(p.size))
+	}
+}
+
+// uploadParts - upload the content of the reader as parts of the session starting after the parts
+// already uploaded, returns the size uploaded by the session.  A failed part is retried and the journal
+// entry, when given, records the progress.
+func uploadParts(session *model.UploadSession, reader io.Reader, totalSize int64, entry *model.UploadJournalEntry, digest io.Writer) (int64, error) {
+	sizer, err := newPartSizer(totalSize)
+	if err != nil {
+		return 0, err
+	}
+
+	buffer := make([]byte, config.MaxPartSize)
+	filled := 0
+	eof := false
+	uploaded := session.UploadedSize
+	partsDone := session.UploadedPartsCount
+
+	for partNumber := partsDone + 1; ; partNumber++ {
+		remaining := totalSize - uploaded
+
+		want := int(sizer.next(remaining, partsDone))
+		if filled < want && !eof {
+			n, err := io.ReadFull(reader, buffer[filled:want])
+			filled += n
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				eof = true
+			} else if err != nil {
+				return uploaded, errors.Wrap(err, "cannot read chunk to buffer")
+			}
+		}
+
+		if filled == 0 {
+			return uploaded, nil
+		}
+
+		partSize := filled
+		if partSize > want {
+			partSize = want
+		}
+
+		part, err := uploadPartWithRetry(session, partNumber, buffer, &partSize, sizer, remaining, partsDone)
+		if err != nil {
+			return uploaded, err
+		}
+		_, _ = digest.Write(buffer[:partSize])
+
+		uploaded += int64(partSize)
+		partsDone = partNumber
+		copy(buffer, buffer[partSize:filled])
+		filled -= partSize
+
+		if entry != nil {
+			entry.PartsDone = partsDone
+			entry.UploadedSize = uploaded
+			config.SaveUploadSession(entry)
+		}
+
+		vlog.Infof("[%s] Uploaded part: %d, size: %s, uploaded: %s of %s, partContentMD5: %s", session.Path,
+			part.PartNumber, util.ByteCountSI(part.PartSize), util.ByteCountSI(uploaded), util.ByteCountSI(totalSize), part.PartContentMD5)
+	}
+}
+
+// Upload the part up to config.PartAttempts times, in auto mode a retry may send a smaller part and
+// the bytes left over are sent with the next part
+func uploadPartWithRetry(session *model.UploadSession, partNumber int, buffer []byte, partSize *int, sizer *partSizer,
+	remaining int64, partsDone int) (*model.UploadPart, error) {
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		part, err := uploadPart(session.UploadSessionID, session.Path, partNumber, buffer[:*partSize])
+		if err == nil {
+			sizer.succeeded(int64(*partSize), time.Since(start))
+			return part, nil
+		}
+
+		if attempt >= config.PartAttempts || net.IsSessionExpired(err) {
+			return nil, err
+		}
+
+		sizer.failed()
+		session.PartRetries++
+		if want := int(sizer.next(remaining, partsDone)); want < *partSize {
+			*partSize = want
+		}
+		vlog.Warnf("[%s] Retrying part %d (attempt %d of %d), size: %s, err: %v", session.Path, partNumber,
+			attempt+1, config.PartAttempts, util.ByteCountSI(int64(*partSize)), err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"github.com/veeva/vvfst/config"
+	"testing"
+	"time"
+)
+
+func TestNewPartSizer(t *testing.T) {
+	const MB = 1024 * 1024
+	const GB = 1024 * MB
+
+	tests := []struct {
+		partSize  string
+		totalSize int64
+		wantSize  int64
+		wantAuto  bool
+		wantErr   bool
+	}{
+		{"", 100 * MB, 5 * MB, false, false},
+		{"", 10 * GB, 25 * MB, false, false},
+		{"", 200 * GB, 50 * MB, false, false},
+		{"10MB", 100 * MB, 10 * MB, false, false},
+		{"10MiB", 100 * MB, 10 * MB, false, false},
+		{"auto", 100 * MB, 5 * MB, true, false},
+		{"5MB", 100 * GB, 0, false, true},
+		{"5XB", 100 * MB, 0, false, true},
+	}
+
+	defer config.SetFlagValue(config.ConfigKeyPartSize, "")
+	for _, test := range tests {
+		config.SetFlagValue(config.ConfigKeyPartSize, test.partSize)
+		sizer, err := newPartSizer(test.totalSize)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("newPartSizer(%d) with part size %q expected error", test.totalSize, test.partSize)
+			}
+			continue
+		}
+		if err != nil || sizer.size != test.wantSize || sizer.auto != test.wantAuto {
+			t.Errorf("newPartSizer(%d) with part size %q = %+v, %v, want size %d, auto %t",
+				test.totalSize, test.partSize, sizer, err, test.wantSize, test.wantAuto)
+		}
+	}
+}
+
+func TestPartSizerNext(t *testing.T) {
+	const MB = 1024 * 1024
+
+	tests := []struct {
+		size      int64
+		remaining int64
+		partsDone int
+		want      int64
+	}{
+		{10 * MB, 100 * MB, 0, 10 * MB},
+		{1 * MB, 100 * MB, 0, config.MinPartSize},
+		{100 * MB, 100 * MB, 0, config.MaxPartSize},
+		{5 * MB, 100 * MB, config.MaxPartCount - 2, 50 * MB},
+		{5 * MB, 100 * MB, config.MaxPartCount, config.MaxPartSize},
+	}
+
+	for _, test := range tests {
+		p := &partSizer{size: test.size}
+		if got := p.next(test.remaining, test.partsDone); got != test.want {
+			t.Errorf("partSizer{size: %d}.next(%d, %d) = %d, want %d", test.size, test.remaining, test.partsDone, got, test.want)
+		}
+	}
+}
+
+func TestPartSizerAuto(t *testing.T) {
+	const MB = 1024 * 1024
+
+	tests := []struct {
+		name     string
+		sizer    partSizer
+		update   func(p *partSizer)
+		wantSize int64
+	}{
+		{"fast part grows", partSizer{size: 10 * MB, auto: true}, func(p *partSizer) { p.succeeded(10*MB, time.Second) }, 20 * MB},
+		{"slow part shrinks", partSizer{size: 10 * MB, auto: true}, func(p *partSizer) { p.succeeded(10*MB, time.Minute) }, 5 * MB},
+		{"normal part keeps", partSizer{size: 10 * MB, auto: true}, func(p *partSizer) { p.succeeded(10*MB, 10*time.Second) }, 10 * MB},
+		{"short last part keeps", partSizer{size: 10 * MB, auto: true}, func(p *partSizer) { p.succeeded(MB, time.Second) }, 10 * MB},
+		{"fast part with errors keeps", partSizer{size: 10 * MB, auto: true, attempts: 4, failures: 1}, func(p *partSizer) { p.succeeded(10*MB, time.Second) }, 10 * MB},
+		{"maximum does not grow", partSizer{size: config.MaxPartSize, auto: true}, func(p *partSizer) { p.succeeded(config.MaxPartSize, time.Second) }, config.MaxPartSize},
+		{"minimum does not shrink", partSizer{size: config.MinPartSize, auto: true}, func(p *partSizer) { p.failed() }, config.MinPartSize},
+		{"failed part shrinks", partSizer{size: 20 * MB, auto: true}, func(p *partSizer) { p.failed() }, 10 * MB},
+		{"fixed size keeps", partSizer{size: 10 * MB}, func(p *partSizer) { p.failed(); p.succeeded(10*MB, time.Second) }, 10 * MB},
+	}
+
+	for _, test := range tests {
+		p := test.sizer
+		test.update(&p)
+		if p.size != test.wantSize {
+			t.Errorf("%s: size = %d, want %d", test.name, p.size, test.wantSize)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// PartialSuffix - suffix of the file being downloaded, it is renamed into place once complete
+const PartialSuffix = ".vvfst-partial"
+
+// BackupSuffix - suffix of the backup of an existing local file replaced by a download
+const BackupSuffix = "~"
+
+// partialMetaSuffix - suffix of the sidecar recording the remote item of the partial file
+const partialMetaSuffix = ".json"
+
+// openPartials - partial files being written, they are cleaned up when the download is cancelled and
+// no partial file is opened once downloads are aborted
+var (
+	openPartials      = map[*partialFile]bool{}
+	openPartialsMutex = &sync.Mutex{}
+	downloadsAborted  bool
+)
+
+// ErrDownloadCancelled - the download was stopped by AbortDownloads
+var ErrDownloadCancelled = errors.New("download cancelled")
+
+// partialFile - the file being downloaded next to the local path.  A download is resumable when the
+// remote size and MD5 are known, an interrupted resumable download keeps the partial file and the
+// sidecar so the next run continues from the end of the partial file, or with the segments which
+// are not written yet when the file is downloaded in byte ranges.
+type partialFile struct {
+	file      *os.File
+	path      string
+	metaPath  string
+	offset    int64
+	resumable bool
+	meta      *model.PartialDownload
+	mutex     sync.Mutex
+}
+
+// openPartial - open the partial file of the item, continuing an earlier download of the same remote
+// item.  A file downloaded in segments of segmentSize is preallocated to the size of the remote item.
+func openPartial(downloadItem *model.DownloadItem, segmentSize int64) (*partialFile, error) {
+	p := &partialFile{
+		path:      downloadItem.LocalPath + PartialSuffix,
+		metaPath:  downloadItem.LocalPath + PartialSuffix + partialMetaSuffix,
+		resumable: downloadItem.Size >= 0 && downloadItem.MD5 != "" && downloadItem.RemotePath != "",
+		meta: &model.PartialDownload{RemotePath: downloadItem.RemotePath, Size: downloadItem.Size,
+			MD5: downloadItem.MD5, SegmentSize: segmentSize},
+	}
+
+	resumed := false
+	if meta := readPartialMeta(p.metaPath); p.resumable && meta.RemotePath == p.meta.RemotePath &&
+		meta.Size == p.meta.Size && meta.MD5 == p.meta.MD5 && meta.SegmentSize == segmentSize {
+		if fi, err := os.Stat(p.path); err == nil {
+			switch {
+			case segmentSize > 0 && fi.Size() == downloadItem.Size:
+				p.meta.SegmentsDone = meta.SegmentsDone
+				resumed = len(meta.SegmentsDone) > 0
+			case segmentSize == 0 && fi.Size() <= downloadItem.Size:
+				p.offset = fi.Size()
+				resumed = p.offset > 0
+			}
+		}
+	}
+
+	// segments are written with WriteAt which cannot be used in append mode
+	flag := os.O_CREATE | os.O_WRONLY
+	if segmentSize == 0 {
+		flag |= os.O_APPEND
+	}
+	if !resumed {
+		flag |= os.O_TRUNC
+	}
+
+	var err error
+	p.file, err = os.OpenFile(p.path, flag, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to create file: %s", p.path)
+	}
+	openPartialsMutex.Lock()
+	if downloadsAborted {
+		openPartialsMutex.Unlock()
+		_ = p.file.Close()
+		return nil, ErrDownloadCancelled
+	}
+	openPartials[p] = true
+	openPartialsMutex.Unlock()
+
+	if segmentSize > 0 && !resumed {
+		if err := p.file.Truncate(downloadItem.Size); err != nil {
+			p.abort()
+			return nil, errors.Wrapf(err, "Failed to allocate file: %s", p.path)
+		}
+	}
+
+	if !p.resumable {
+		_ = os.Remove(p.metaPath)
+		return p, nil
+	}
+
+	if resumed {
+		if segmentSize > 0 {
+			vlog.Infof("Resuming download of %s, %d segment(s) already downloaded", downloadItem.RemotePath, len(p.meta.SegmentsDone))
+		} else {
+			vlog.Infof("Resuming download of %s from %s", downloadItem.RemotePath, util.ByteCountSI(p.offset))
+		}
+		return p, nil
+	}
+
+	if err := writePartialMeta(p.metaPath, p.meta); err != nil {
+		p.discard()
+		return nil, err
+	}
+	return p, nil
+}
+
+// segmentDone - record the segment as written so an interrupted download does not fetch it again
+func (p *partialFile) segmentDone(index int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.meta.SegmentsDone = append(p.meta.SegmentsDone, index)
+	if !p.resumable {
+		return nil
+	}
+	return writePartialMeta(p.metaPath, p.meta)
+}
+
+// restart - discard the content downloaded so far, when the server does not honor the range request
+func (p *partialFile) restart() error {
+	p.offset = 0
+	if err := p.file.Truncate(0); err != nil {
+		return errors.Wrapf(err, "Failed to truncate file: %s", p.path)
+	}
+	return nil
+}
+
+// hashContent - write the content downloaded so far to the digest
+func (p *partialFile) hashContent(digest io.Writer) error {
+	if p.offset == 0 {
+		return nil
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open file: %s", p.path)
+	}
+	defer f.Close()
+
+	if _, err := io.CopyN(digest, f, p.offset); err != nil {
+		return errors.Wrapf(err, "Failed to read file: %s", p.path)
+	}
+	return nil
+}
+
+// abort - close the partial file after a failure, it is kept only when the download can be resumed
+func (p *partialFile) abort() {
+	p.close()
+	if !p.resumable {
+		_ = os.Remove(p.path)
+	}
+}
+
+// discard - close and remove the partial file and the sidecar, the content does not match the remote item
+func (p *partialFile) discard() {
+	p.close()
+	_ = os.Remove(p.path)
+	_ = os.Remove(p.metaPath)
+}
+
+// complete - flush the partial file to disk and rename it over the local path, an existing local file
+// is renamed to the backup first when requested
+func (p *partialFile) complete(localPath string, backup bool) error {
+	err := p.file.Sync()
+	p.close()
+	if err != nil {
+		_ = os.Remove(p.path)
+		return errors.Wrapf(err, "Failed to write file: %s", p.path)
+	}
+
+	backedUp := false
+	if backup {
+		if fi, err := os.Stat(localPath); err == nil && fi.Mode().IsRegular() {
+			if err := os.Rename(localPath, localPath+BackupSuffix); err != nil {
+				return errors.Wrapf(err, "Failed to back up %s", localPath)
+			}
+			backedUp = true
+		}
+	}
+
+	if err := os.Rename(p.path, localPath); err != nil {
+		// the original file is put back so a failed download never leaves it moved
+		if backedUp {
+			if restoreErr := os.Rename(localPath+BackupSuffix, localPath); restoreErr != nil {
+				vlog.Errorf("Failed to restore %s from its backup: %v", localPath, restoreErr)
+			}
+		}
+		return errors.Wrapf(err, "Failed to rename %s to %s", p.path, localPath)
+	}
+	_ = os.Remove(p.metaPath)
+	return nil
+}
+
+func (p *partialFile) close() {
+	openPartialsMutex.Lock()
+	delete(openPartials, p)
+	openPartialsMutex.Unlock()
+	_ = p.file.Close()
+}
+
+// AbortDownloads - close the files of the downloads in progress when the command is cancelled, a partial
+// file is kept only when the download can be resumed.  The downloads in progress and any download started
+// afterwards fail with ErrDownloadCancelled.
+func AbortDownloads() {
+	openPartialsMutex.Lock()
+	downloadsAborted = true
+	partials := make([]*partialFile, 0, len(openPartials))
+	for p := range openPartials {
+		partials = append(partials, p)
+	}
+	openPartialsMutex.Unlock()
+
+	for _, p := range partials {
+		p.abort()
+	}
+}
+
+// aborted - downloads were cancelled by AbortDownloads
+func aborted() bool {
+	openPartialsMutex.Lock()
+	defer openPartialsMutex.Unlock()
+	return downloadsAborted
+}
+
+func readPartialMeta(metaPath string) *model.PartialDownload {
+	meta := &model.PartialDownload{}
+	content, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		return meta
+	}
+	if err := json.Unmarshal(content, meta); err != nil {
+		vlog.Debugf("Ignoring invalid partial download record %s: %v", metaPath, err)
+	}
+	return meta
+}
+
+func writePartialMeta(metaPath string, meta *model.PartialDownload) error {
+	content, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(metaPath, content, 0644); err != nil {
+		return errors.Wrapf(err, "Failed to create file: %s", metaPath)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompleteRestoresBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vvfst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "a.csv")
+	if err := ioutil.WriteFile(localPath, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := ioutil.TempFile(dir, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the partial file does not exist, so renaming it over the local path fails
+	p := &partialFile{file: file, path: filepath.Join(dir, "missing"+PartialSuffix)}
+	if err := p.complete(localPath, true); err == nil {
+		t.Fatal("complete() expected error")
+	}
+
+	content, err := ioutil.ReadFile(localPath)
+	if err != nil || string(content) != "original" {
+		t.Errorf("local file = %q, %v, want original", content, err)
+	}
+	if _, err := os.Stat(localPath + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("backup %s still exists", localPath+BackupSuffix)
+	}
+}
@@ -0,0 +1,355 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"fmt"
+	"github.com/schollz/progressbar/v3"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"golang.org/x/crypto/ssh/terminal"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Refresh interval of the progress display on a terminal and of the progress log otherwise
+const (
+	progressRedrawInterval = 200 * time.Millisecond
+	progressLogInterval    = 10 * time.Second
+	progressNameWidth      = 40
+)
+
+// progressTracker - receives the bytes of one file, either a progressbar of its own or a line of the
+// progress display when one is running
+type progressTracker interface {
+	io.Writer
+	Add64(n int64) error
+	done()
+}
+
+var (
+	activeProgress      *Progress
+	activeProgressMutex = &sync.Mutex{}
+)
+
+// buildProgressbar - the progress of one file, shown as a line of the running progress display or as
+// a progressbar of its own.  The size is negative when it is not known such as for a job report.
+func buildProgressbar(name string, size int64) progressTracker {
+	activeProgressMutex.Lock()
+	p := activeProgress
+	activeProgressMutex.Unlock()
+	if p != nil {
+		return p.track(name, size)
+	}
+
+	options := []progressbar.Option{
+		progressbar.OptionSetDescription(fmt.Sprintf("downloading %s ", name)),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetWidth(10),
+		progressbar.OptionThrottle(65 * time.Millisecond),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetRenderBlankState(true),
+		progressbar.OptionOnCompletion(func() {
+			_, _ = fmt.Fprint(os.Stderr, "\n")
+		}),
+		progressbar.OptionSpinnerType(14),
+		progressbar.OptionSetTheme(progressbar.Theme{Saucer: "=", SaucerPadding: " ", BarStart: ">", BarEnd: "|"}),
+		progressbar.OptionFullWidth(),
+	}
+	// a spinner of unknown size shows the bytes received instead of a count out of -1
+	if size < 0 {
+		options = append(options, progressbar.OptionShowBytes(true))
+	} else {
+		options = append(options, progressbar.OptionShowIts())
+	}
+
+	bar := &fileProgressbar{ProgressBar: progressbar.NewOptions64(size, options...), size: size}
+	_ = bar.RenderBlank()
+	return bar
+}
+
+// fileProgressbar - progressbar of a single file, the line is ended when the file is done before the
+// bar is complete such as a failed download or a file of unknown size
+type fileProgressbar struct {
+	*progressbar.ProgressBar
+	size    int64
+	current int64
+}
+
+func (b *fileProgressbar) Write(p []byte) (int, error) {
+	atomic.AddInt64(&b.current, int64(len(p)))
+	return b.ProgressBar.Write(p)
+}
+
+func (b *fileProgressbar) Add64(n int64) error {
+	atomic.AddInt64(&b.current, n)
+	return b.ProgressBar.Add64(n)
+}
+
+func (b *fileProgressbar) done() {
+	if b.size < 0 || atomic.LoadInt64(&b.current) < b.size {
+		_, _ = fmt.Fprint(os.Stderr, "\n")
+	}
+}
+
+// Progress - progress display of a command transferring many files with a worker pool.  On a terminal it
+// shows one line per file in progress and a total line with the files and bytes done, the throughput and
+// the ETA, logs are written above the lines.  Otherwise the totals are logged periodically.
+type Progress struct {
+	mutex       sync.Mutex
+	terminal    bool
+	start       time.Time
+	lines       []*progressLine
+	drawn       int
+	filesQueued int
+	filesDone   int
+	bytesQueued int64
+	bytesDone   int64
+	transferred int64
+	listed      bool
+	stop        chan struct{}
+	stopped     chan struct{}
+}
+
+// progressLine - a file in progress on the display
+type progressLine struct {
+	progress *Progress
+	name     string
+	size     int64
+	current  int64
+}
+
+// StartProgress - start the progress display, file progress is shown on it until it is stopped
+func StartProgress() *Progress {
+	p := &Progress{
+		terminal: runtime.GOOS != "windows" && terminal.IsTerminal(int(os.Stderr.Fd())),
+		start:    time.Now(),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	activeProgressMutex.Lock()
+	activeProgress = p
+	activeProgressMutex.Unlock()
+
+	interval := progressLogInterval
+	if p.terminal {
+		interval = progressRedrawInterval
+		vlog.SetOutput(&progressLogWriter{progress: p})
+	}
+
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.refresh()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Stop - draw the final totals and stop the display
+func (p *Progress) Stop() {
+	close(p.stop)
+	<-p.stopped
+
+	activeProgressMutex.Lock()
+	activeProgress = nil
+	activeProgressMutex.Unlock()
+
+	if p.terminal {
+		p.mutex.Lock()
+		p.lines = nil
+		p.redraw()
+		p.mutex.Unlock()
+		vlog.SetOutput(os.Stdout)
+	}
+}
+
+// Queued - count a file queued for transfer in the totals
+func (p *Progress) Queued(size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.filesQueued++
+	if size > 0 {
+		p.bytesQueued += size
+	}
+}
+
+// Listed - every file is queued, the ETA is shown from now on
+func (p *Progress) Listed() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.listed = true
+}
+
+// Finished - count a queued file as done, whether it was transferred, skipped or failed
+func (p *Progress) Finished(size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.filesDone++
+	if size > 0 {
+		p.bytesDone += size
+	}
+}
+
+func (p *Progress) track(name string, size int64) progressTracker {
+	line := &progressLine{progress: p, name: name, size: size}
+
+	p.mutex.Lock()
+	p.lines = append(p.lines, line)
+	p.mutex.Unlock()
+	return line
+}
+
+// Write - bytes received, they count towards the throughput
+func (l *progressLine) Write(b []byte) (int, error) {
+	l.progress.mutex.Lock()
+	l.current += int64(len(b))
+	l.progress.transferred += int64(len(b))
+	l.progress.mutex.Unlock()
+	return len(b), nil
+}
+
+// Add64 - bytes already on disk such as a resumed download, they do not count towards the throughput
+func (l *progressLine) Add64(n int64) error {
+	l.progress.mutex.Lock()
+	l.current += n
+	l.progress.mutex.Unlock()
+	return nil
+}
+
+func (l *progressLine) done() {
+	p := l.progress
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, line := range p.lines {
+		if line == l {
+			p.lines = append(p.lines[:i], p.lines[i+1:]...)
+			break
+		}
+	}
+}
+
+func (p *Progress) refresh() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.terminal {
+		p.redraw()
+		return
+	}
+	vlog.Infof("Progress: %s", p.summary())
+}
+
+// summary - the total line, the mutex must be held
+func (p *Progress) summary() string {
+	done := p.bytesDone
+	for _, line := range p.lines {
+		done += line.current
+	}
+
+	files := fmt.Sprintf("%d of %d files", p.filesDone, p.filesQueued)
+	bytes := fmt.Sprintf("%s of %s", util.ByteCountSI(done), util.ByteCountSI(p.bytesQueued))
+	if !p.listed {
+		files = fmt.Sprintf("%d of %d+ files", p.filesDone, p.filesQueued)
+		bytes = fmt.Sprintf("%s of %s+", util.ByteCountSI(done), util.ByteCountSI(p.bytesQueued))
+	}
+
+	rate := float64(p.transferred) / time.Since(p.start).Seconds()
+	eta := "--"
+	if p.listed && rate > 0 && done <= p.bytesQueued {
+		eta = (time.Duration(float64(p.bytesQueued-done)/rate) * time.Second).String()
+	}
+	return fmt.Sprintf("%s, %s, %s/s, ETA %s", files, bytes, util.ByteCountSI(int64(rate)), eta)
+}
+
+// redraw - draw the file lines and the total line over the lines drawn before, the mutex must be held
+func (p *Progress) redraw() {
+	width := 80
+	if w, _, err := terminal.GetSize(int(os.Stderr.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	var sb strings.Builder
+	p.clear(&sb)
+	for _, line := range p.lines {
+		sb.WriteString(truncateLine(line.render(), width))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(truncateLine("Total: "+p.summary(), width))
+	sb.WriteString("\n")
+	p.drawn = len(p.lines) + 1
+	_, _ = fmt.Fprint(os.Stderr, sb.String())
+}
+
+// clear - move the cursor to the first line drawn and clear the screen below it
+func (p *Progress) clear(sb *strings.Builder) {
+	if p.drawn > 0 {
+		sb.WriteString(fmt.Sprintf("\x1b[%dA", p.drawn))
+	}
+	sb.WriteString("\r\x1b[J")
+	p.drawn = 0
+}
+
+func (l *progressLine) render() string {
+	name := util.FixedWidth(l.name, progressNameWidth, true)
+	if l.size <= 0 {
+		return fmt.Sprintf("  %s  %s", name, util.ByteCountSI(l.current))
+	}
+	return fmt.Sprintf("  %s  %3d%%  %s of %s", name, l.current*100/l.size, util.ByteCountSI(l.current), util.ByteCountSI(l.size))
+}
+
+func truncateLine(line string, width int) string {
+	if len(line) >= width {
+		return line[:width-1]
+	}
+	return line
+}
+
+// progressLogWriter - writes the log above the progress display, both on stderr so the output of the
+// command on stdout never carries the log
+type progressLogWriter struct {
+	progress *Progress
+}
+
+func (w *progressLogWriter) Write(b []byte) (int, error) {
+	p := w.progress
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var sb strings.Builder
+	p.clear(&sb)
+	_, _ = fmt.Fprint(os.Stderr, sb.String())
+	n, err := os.Stderr.Write(b)
+	p.redraw()
+	return n, err
+}
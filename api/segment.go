@@ -0,0 +1,189 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var errRangeNotSupported = errors.New("range request is not supported")
+
+// connectionSlots - content requests in flight across every download of the command, so a pool of workers
+// downloading large files in byte ranges shares the connections instead of opening threads x threads
+var connectionSlots chan struct{}
+
+// SetConnectionLimit - the number of content requests in flight across every download, set before the
+// downloads start
+func SetConnectionLimit(n int) {
+	if n > 0 {
+		connectionSlots = make(chan struct{}, n)
+	}
+}
+
+// acquireConnection - wait for a free connection, the returned function releases it once the response
+// is read
+func acquireConnection() func() {
+	if connectionSlots == nil {
+		return func() {}
+	}
+	connectionSlots <- struct{}{}
+	return func() {
+		<-connectionSlots
+	}
+}
+
+// downloadSegmentSize - segment size when the file is large enough to be downloaded in concurrent
+// byte ranges, otherwise 0
+func downloadSegmentSize(downloadItem *model.DownloadItem) int64 {
+	segmentSize := config.SegmentSize()
+	if downloadItem.Threads < 2 || downloadItem.Size < config.SegmentThreshold() || downloadItem.Size <= segmentSize {
+		return 0
+	}
+	return segmentSize
+}
+
+// downloadSegments - download the segments of the file which are not written yet with concurrent range
+// requests, every segment is written at its offset of the preallocated partial file.  Returns the size
+// and MD5 of the whole file.
+func downloadSegments(downloadItem *model.DownloadItem, partial *partialFile) (int64, string, error) {
+	size := downloadItem.Size
+	segmentSize := partial.meta.SegmentSize
+	count := int((size + segmentSize - 1) / segmentSize)
+
+	done := map[int]bool{}
+	for _, index := range partial.meta.SegmentsDone {
+		done[index] = true
+	}
+
+	bar := buildProgressbar(filepath.Base(downloadItem.LocalPath), size)
+	defer bar.done()
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	stop := make(chan struct{})
+	ch := make(chan int)
+
+	for i := downloadItem.Threads; i > 0; i-- {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range ch {
+				if err := downloadSegment(downloadItem, partial, index, bar); err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(stop)
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for index := 0; index < count; index++ {
+		if done[index] {
+			_ = bar.Add64(segmentLength(size, segmentSize, index))
+			continue
+		}
+
+		select {
+		case ch <- index:
+		case <-stop:
+			break feed
+		}
+	}
+	close(ch)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, "", firstErr
+	}
+
+	partial.offset = size
+	digest := md5.New()
+	if err := partial.hashContent(digest); err != nil {
+		return size, "", err
+	}
+	return size, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// Download a single segment and write it at its offset
+func downloadSegment(downloadItem *model.DownloadItem, partial *partialFile, index int, bar progressTracker) error {
+	segmentSize := partial.meta.SegmentSize
+	start := int64(index) * segmentSize
+	length := segmentLength(downloadItem.Size, segmentSize, index)
+	vlog.Debugf("Download segment %d of file: %s, bytes %d-%d", index, downloadItem.RemotePath, start, start+length-1)
+
+	defer acquireConnection()()
+	resp, err := requestContent(downloadItem, start, start+length-1)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := resp.RawBody().Close()
+		if err != nil {
+			vlog.Errorf("Error closing http response")
+		}
+	}()
+
+	switch {
+	case resp.StatusCode() >= http.StatusBadRequest:
+		return errors.Errorf("Failed to download file: %s, status: %s", downloadItem.RemotePath, resp.Status())
+	case resp.StatusCode() != http.StatusPartialContent:
+		return errRangeNotSupported
+	}
+
+	writer := &offsetWriter{file: partial.file, offset: start}
+	n, err := io.Copy(io.MultiWriter(writer, bar), io.LimitReader(resp.RawBody(), length))
+	if err != nil {
+		return errors.Wrapf(err, "Failed to download file: %s", downloadItem.RemotePath)
+	}
+	if n != length {
+		return errors.Errorf("Failed to download file: %s, received %d of %d bytes of segment %d", downloadItem.RemotePath, n, length, index)
+	}
+	return partial.segmentDone(index)
+}
+
+func segmentLength(size, segmentSize int64, index int) int64 {
+	start := int64(index) * segmentSize
+	if start+segmentSize > size {
+		return size - start
+	}
+	return segmentSize
+}
+
+// offsetWriter - writes sequentially from the offset of the file
+type offsetWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,152 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// UploadStream - upload content of unknown size such as stdin without a temporary copy on disk.  The
+// content is uploaded with a single request up to the multipart threshold, larger content fails as the
+// upload session of a multipart upload is created with the size of the file.
+func UploadStream(reader io.Reader, remotePath string, overwriteOpt bool) *model.TransferResult {
+	result := newTransferResult("-", remotePath)
+	result.Method = model.TransferSimple
+	threshold := config.MultipartThreshold()
+
+	// one byte more than the threshold tells content of exactly the threshold from larger content
+	buffer := make([]byte, threshold+1)
+	n, err := io.ReadFull(reader, buffer)
+	if err == nil {
+		return finishTransfer(result, errors.Errorf("Input is larger than the multipart threshold of %s, the size "+
+			"of the file is needed to begin a multipart upload: upload it from a file or raise --multipart-threshold up to %s",
+			util.ByteCountIEC(threshold), util.ByteCountIEC(config.Size50MB)))
+	}
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		return finishTransfer(result, errors.Wrap(err, "Failed to read input"))
+	}
+
+	digest := md5.New()
+	result.Size = int64(n)
+	err = uploadContent(remotePath, int64(n), io.TeeReader(bytes.NewReader(buffer[:n]), digest), overwriteOpt)
+	if err == nil {
+		result.MD5 = hex.EncodeToString(digest.Sum(nil))
+	}
+	return finishTransfer(result, err)
+}
+
+// UploadReader - upload content of known size such as an archive entry without a temporary copy on disk,
+// content larger than the multipart threshold is uploaded in parts as it is read
+func UploadReader(reader io.Reader, size int64, localPath, remotePath string, overwriteOpt bool) *model.TransferResult {
+	result := newTransferResult(localPath, remotePath)
+	result.Size = size
+	digest := md5.New()
+
+	if size <= config.MultipartThreshold() {
+		result.Method = model.TransferSimple
+		err := uploadContent(remotePath, size, io.TeeReader(reader, digest), overwriteOpt)
+		if err == nil {
+			result.MD5 = hex.EncodeToString(digest.Sum(nil))
+		}
+		return finishTransfer(result, err)
+	}
+
+	result.Method = model.TransferMultipart
+	uploadSession, err := multipartUploadBegin(remotePath, size, overwriteOpt)
+	if err != nil {
+		return finishTransfer(result, err)
+	}
+
+	uploaded, err := uploadParts(uploadSession, reader, size, nil, digest)
+	result.Attempts += uploadSession.PartRetries
+	if err == nil && uploaded != size {
+		err = errors.Errorf("Failed to read input: %s, read %d of %d bytes", localPath, uploaded, size)
+	}
+	if err != nil {
+		return finishTransfer(result, err)
+	}
+
+	err = MultipartUploadCommit(uploadSession)
+	if err == nil {
+		result.MD5 = hex.EncodeToString(digest.Sum(nil))
+	}
+	return finishTransfer(result, err)
+}
+
+// DownloadStream - write the content of the remote item from start to end inclusive to the writer, a
+// negative end writes up to the end of the item.  The whole content is verified against the size and MD5
+// of the remote item once written, a byte range is not verified.  Returns the number of bytes written.
+func DownloadStream(downloadItem *model.DownloadItem, writer io.Writer, start, end int64, showProgress bool) (int64, error) {
+	ranged := start > 0 || end >= 0
+	resp, err := requestContent(downloadItem, start, end)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		err := resp.RawBody().Close()
+		if err != nil {
+			vlog.Errorf("Error closing http response")
+		}
+	}()
+
+	var body io.Reader = resp.RawBody()
+	switch {
+	case resp.StatusCode() == http.StatusRequestedRangeNotSatisfiable:
+		return 0, errors.Errorf("Byte range %d-%d is outside of file: %s, size: %d", start, end, downloadItem.RemotePath, downloadItem.Size)
+	case resp.StatusCode() >= http.StatusBadRequest:
+		return 0, errors.Errorf("Failed to download file: %s, status: %s", downloadItem.RemotePath, resp.Status())
+	case ranged && resp.StatusCode() != http.StatusPartialContent:
+		// the server sent the whole content, skip to the range
+		if _, err := io.CopyN(ioutil.Discard, body, start); err != nil {
+			return 0, errors.Wrapf(err, "Failed to download file: %s", downloadItem.RemotePath)
+		}
+		if end >= 0 {
+			body = io.LimitReader(body, end-start+1)
+		}
+	}
+
+	digest := md5.New()
+	writers := []io.Writer{writer, digest}
+	if showProgress {
+		size := downloadItem.Size
+		if ranged {
+			size = -1
+		}
+		bar := buildProgressbar(downloadItem.RemotePath, size)
+		defer bar.done()
+		writers = append(writers, bar)
+	}
+
+	n, err := io.Copy(io.MultiWriter(writers...), body)
+	if err != nil {
+		return n, errors.Wrapf(err, "Failed to download file: %s", downloadItem.RemotePath)
+	}
+	if ranged {
+		return n, nil
+	}
+	_, err = verifyDownload(downloadItem, n, hex.EncodeToString(digest.Sum(nil)))
+	return n, err
+}
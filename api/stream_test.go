@@ -0,0 +1,25 @@
+package api
+
+import (
+	"bytes"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"strings"
+	"testing"
+)
+
+func TestUploadStreamLargerThanThreshold(t *testing.T) {
+	const threshold = 5 * 1024 * 1024
+	config.SetFlagValue(config.ConfigKeyMultipartThreshold, "5MiB")
+	defer config.SetFlagValue(config.ConfigKeyMultipartThreshold, "")
+
+	// content larger than the threshold fails before any request is sent
+	tests := []int{threshold + 1, 2 * threshold}
+	for _, size := range tests {
+		result := UploadStream(bytes.NewReader(make([]byte, size)), "/exports/db.gz", false)
+		if result.Status != model.TransferFailed || result.Err == nil ||
+			!strings.Contains(result.Err.Error(), "larger than the multipart threshold") {
+			t.Errorf("UploadStream(%d bytes) = %s, %v, want failed as larger than the multipart threshold", size, result.Status, result.Err)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const skipReasonUnsafePath = "UNSAFE_PATH"
+
+var fromArchiveOpt bool
+
+// archiveEntry - a file or folder inside an archive, the content is read with open while the archive is walked
+type archiveEntry struct {
+	name    string
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	open    func() (io.ReadCloser, error)
+	// linkName - target of a tar hard link, the entry has no content of its own
+	linkName string
+}
+
+// walkArchive - call visit for every entry of a .zip, .tar, .tar.gz or .tgz archive in the order of the archive
+func walkArchive(filename string, visit func(entry *archiveEntry) error) error {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return walkZip(filename, visit)
+	case strings.HasSuffix(name, ".tar"):
+		return walkTar(filename, false, visit)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return walkTar(filename, true, visit)
+	}
+	return fmt.Errorf("unsupported archive format: %s, only .zip, .tar, .tar.gz and .tgz are supported", filename)
+}
+
+func walkZip(filename string, visit func(entry *archiveEntry) error) error {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		f := f
+		err := visit(&archiveEntry{
+			name:    f.Name,
+			mode:    f.Mode(),
+			size:    int64(f.UncompressedSize64),
+			modTime: f.Modified,
+			open:    f.Open,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(filename string, gzipped bool, visit func(entry *archiveEntry) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = bufio.NewReader(file)
+	if gzipped {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filename, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filename, err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		entry := &archiveEntry{
+			name:    header.Name,
+			mode:    header.FileInfo().Mode(),
+			size:    header.Size,
+			modTime: header.ModTime,
+			open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(tr), nil
+			},
+		}
+		if header.Typeflag == tar.TypeLink {
+			entry.linkName = header.Linkname
+		}
+
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveRelPath - path of the entry relative to the archive root, false when the entry points outside of it
+func archiveRelPath(name string) (string, bool) {
+	name = strings.Replace(name, "\\", "/", -1)
+	if path.IsAbs(name) {
+		return "", false
+	}
+
+	relPath := path.Clean(name)
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", false
+	}
+	return relPath, true
+}
+
+// archiveUpload - an accepted archive entry handed to the worker pool
+type archiveUpload struct {
+	open       func() (io.ReadCloser, error)
+	size       int64
+	localPath  string
+	remotePath string
+}
+
+// archiveLink - a tar hard link, uploaded with the content of its target in a later pass over the archive
+type archiveLink struct {
+	relPath    string
+	localPath  string
+	remotePath string
+}
+
+// uploadArchive - stream every entry of the archive into the remote folder, keeping the folder structure
+// of the archive.  Nothing is extracted to disk, the entries are uploaded by the worker pool while the
+// archive is read.
+func uploadArchive(archive, remoteItem string, report *transferReport) error {
+	if stat, err := os.Stat(archive); err != nil || !stat.Mode().IsRegular() {
+		return fmt.Errorf("%s not found", archive)
+	}
+
+	if util.EndWithFileSeparator(remoteItem) {
+		remoteItem = util.TrimLastChar(remoteItem)
+	}
+
+	filter, err := buildItemFilter("")
+	if err != nil {
+		return err
+	}
+
+	// an entry can only be read while the archive is walked, so the queued entries are read into memory
+	// first and the channel is unbuffered: every worker holds the entry it uploads and the walk holds the
+	// next one, up to (threads + 1) x the multipart threshold in memory
+	var wg sync.WaitGroup
+	ch := make(chan *archiveUpload)
+
+	// run worker pool
+	for i := threadCnt; i > 0; i-- {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for item := range ch {
+				if report.stopped() {
+					continue
+				}
+				report.add(uploadArchiveEntry(item))
+			}
+		}()
+	}
+
+	// the archive is walked in a single goroutine and the created folders need no lock
+	folders := newFolderCreator(filter.selectsFiles(), func(relPath string) bool {
+		if relPath != "." && filter.skipDir(relPath) {
+			vlog.Debugf("Excluded folder: %s", relPath)
+			return false
+		}
+
+		result := api.CreateFolder(remoteItemPath(remoteItem, relPath), true, false)
+		if result.Status == model.TransferFailed {
+			result.LocalPath = filepath.ToSlash(archive) + "/" + relPath
+			report.add(result)
+			return false
+		}
+		return true
+	})
+
+	if !folders.visitDir(".") {
+		close(ch)
+		wg.Wait()
+		return report.summarize("Upload")
+	}
+
+	// upload - read the content of the entry into memory and queue it to the worker pool, an entry above the
+	// multipart threshold is streamed in parts right away instead of being held in memory
+	upload := func(entry *archiveEntry, relPath, localPath, remotePath string) {
+		if !filter.acceptFile(relPath, entry.size, entry.modTime) {
+			vlog.Debugf("Excluded file: %s", localPath)
+			return
+		}
+		if !folders.folder(path.Dir(relPath)) {
+			return
+		}
+
+		item := &archiveUpload{open: entry.open, size: entry.size, localPath: localPath, remotePath: remotePath}
+		if entry.size > config.MultipartThreshold() {
+			report.add(uploadArchiveEntry(item))
+			return
+		}
+
+		content, err := readArchiveEntry(entry)
+		if err != nil {
+			report.add(newFailedResult(localPath, remotePath, err))
+			return
+		}
+		item.open = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(content)), nil
+		}
+		ch <- item
+	}
+
+	// hard links by the path of their target, the same target may be linked more than once
+	links := map[string][]*archiveLink{}
+
+	err = walkArchive(archive, func(entry *archiveEntry) error {
+		if report.stopped() {
+			return errTransferStopped
+		}
+
+		relPath, ok := archiveRelPath(entry.name)
+		if !ok {
+			localPath := filepath.ToSlash(archive) + "/" + entry.name
+			err := fmt.Errorf("entry points outside of the archive folder")
+			vlog.Warnf("Skipped %s: %v", localPath, err)
+			report.add(newSkippedResult(localPath, remoteItem, skipReasonUnsafePath, err))
+			return nil
+		}
+		if relPath == "." {
+			return nil
+		}
+		localPath := filepath.ToSlash(archive) + "/" + relPath
+		remotePath := remoteItemPath(remoteItem, relPath)
+
+		switch {
+		case entry.mode.IsDir():
+			folders.visitDir(relPath)
+
+		case entry.linkName != "":
+			if filter.excludedPath(relPath, false) {
+				vlog.Debugf("Excluded file: %s", localPath)
+				return nil
+			}
+			target, ok := archiveRelPath(entry.linkName)
+			if !ok {
+				err := fmt.Errorf("hard link target %s points outside of the archive folder", entry.linkName)
+				vlog.Warnf("Skipped %s: %v", localPath, err)
+				report.add(newSkippedResult(localPath, remotePath, skipReasonUnsafePath, err))
+				return nil
+			}
+			links[target] = append(links[target], &archiveLink{relPath: relPath, localPath: localPath, remotePath: remotePath})
+
+		case entry.mode&os.ModeSymlink != 0:
+			err := fmt.Errorf("symbolic link is skipped")
+			vlog.Warnf("Skipped %s: %v", localPath, err)
+			report.add(newSkippedResult(localPath, remotePath, skipReasonSymlink, err))
+
+		case !entry.mode.IsRegular():
+			err := fmt.Errorf("%s is skipped", describeFileType(entry.mode))
+			vlog.Warnf("Skipped %s: %v", localPath, err)
+			report.add(newSkippedResult(localPath, remotePath, skipReasonSpecialFile, err))
+
+		default:
+			upload(entry, relPath, localPath, remotePath)
+		}
+		return nil
+	})
+
+	// a hard link has no content of its own, every pass reads the archive again and uploads the content
+	// of each target under the name of one of its links
+	for err == nil && len(links) > 0 {
+		found := map[string]bool{}
+		err = walkArchive(archive, func(entry *archiveEntry) error {
+			if report.stopped() {
+				return errTransferStopped
+			}
+
+			relPath, ok := archiveRelPath(entry.name)
+			if !ok || entry.linkName != "" || !entry.mode.IsRegular() || found[relPath] || len(links[relPath]) == 0 {
+				return nil
+			}
+			found[relPath] = true
+
+			link := links[relPath][0]
+			if links[relPath] = links[relPath][1:]; len(links[relPath]) == 0 {
+				delete(links, relPath)
+			}
+			upload(entry, link.relPath, link.localPath, link.remotePath)
+			return nil
+		})
+
+		// the links left after a pass which did not find their target point to no file of the archive
+		for target, targetLinks := range links {
+			if found[target] {
+				continue
+			}
+			for _, link := range targetLinks {
+				err := fmt.Errorf("hard link target %s is not a file of the archive", target)
+				vlog.Errorf("Failed to upload %s: %v", link.localPath, err)
+				report.add(newFailedResult(link.localPath, link.remotePath, err))
+			}
+			delete(links, target)
+		}
+	}
+
+	close(ch)
+	wg.Wait()
+
+	if err != nil && err != errTransferStopped {
+		return err
+	}
+	return report.summarize("Upload")
+}
+
+// uploadArchiveEntry - upload the content of an archive entry
+func uploadArchiveEntry(item *archiveUpload) *model.TransferResult {
+	content, err := item.open()
+	if err != nil {
+		return newFailedResult(item.localPath, item.remotePath, err)
+	}
+	defer content.Close()
+
+	return api.UploadReader(content, item.size, item.localPath, item.remotePath, overwriteOpt)
+}
+
+// readArchiveEntry - read the whole content of the entry, which must match the size of its header
+func readArchiveEntry(entry *archiveEntry) ([]byte, error) {
+	content, err := entry.open()
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != entry.size {
+		return nil, fmt.Errorf("read %d of %d bytes", len(data), entry.size)
+	}
+	return data, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestArchiveRelPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"a.txt", "a.txt", true},
+		{"inbox/2020/a.xml", "inbox/2020/a.xml", true},
+		{"./inbox/", "inbox", true},
+		{"inbox/../a.txt", "a.txt", true},
+		{`inbox\2020\a.xml`, "inbox/2020/a.xml", true},
+		{"./", ".", true},
+		{"../a.txt", "", false},
+		{"inbox/../../a.txt", "", false},
+		{"..", "", false},
+		{"/etc/passwd", "", false},
+		{`\etc\passwd`, "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := archiveRelPath(test.name)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("archiveRelPath(%q) = %q, %t, want %q, %t", test.name, got, ok, test.want, test.wantOK)
+		}
+	}
+}
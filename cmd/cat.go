@@ -0,0 +1,172 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stdoutPath - local path of download which writes the content to stdout
+const stdoutPath = "-"
+
+var (
+	headOpt  int
+	bytesOpt string
+)
+
+var errHeadDone = errors.New("head lines written")
+
+var catCmd = &cobra.Command{
+	Use:   "cat <remote-file>",
+	Short: "Print the content of a remote file",
+	Long: `Print the content of a remote file to stdout, logs are written to stderr so the content can be piped
+into another tool.  Same as download <remote-file> -
+
+--bytes selects a byte range: START-END (inclusive), START- (from START to the end) or -N (the last N bytes)`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithAutoLogin(cmd, args, catCommand)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(catCmd)
+	catCmd.Flags().IntVar(&headOpt, "head", 0, "Print only the first N lines")
+	catCmd.Flags().StringVar(&bytesOpt, "bytes", "", "Print only the byte range START-END, START- or -N")
+}
+
+func catCommand(cmd *cobra.Command, args []string) error {
+	vlog.SetOutput(os.Stderr)
+	if len(args) != 1 {
+		return fmt.Errorf("missing required arg <remote-file>")
+	}
+	if headOpt < 0 {
+		return fmt.Errorf("--head must not be negative")
+	}
+
+	cmd.SilenceUsage = true
+	return writeRemoteFile(strings.TrimSpace(args[0]), bytesOpt, headOpt, false)
+}
+
+// writeRemoteFile - write the content of the remote file to stdout, limited to the byte range and the
+// number of lines when given
+func writeRemoteFile(remotePath, byteRange string, lines int, showProgress bool) error {
+	item, err := api.StatItem(remotePath)
+	if err != nil {
+		return err
+	}
+	if item.Kind == "folder" {
+		return fmt.Errorf("%s is not a file", remotePath)
+	}
+
+	start, end := int64(0), int64(-1)
+	if byteRange != "" {
+		if start, end, err = parseByteRange(byteRange, item.Size); err != nil {
+			return err
+		}
+		if end < start {
+			return nil
+		}
+	}
+
+	stdout := bufio.NewWriter(os.Stdout)
+	var writer io.Writer = stdout
+	if lines > 0 {
+		writer = &headWriter{writer: stdout, lines: lines}
+	}
+
+	downloadItem := &model.DownloadItem{RemotePath: item.Path, Size: item.Size, MD5: item.MD5, LocalPath: stdoutPath}
+	_, err = api.DownloadStream(downloadItem, writer, start, end, showProgress)
+	if flushErr := stdout.Flush(); err == nil || errors.Cause(err) == errHeadDone {
+		err = flushErr
+	}
+	return err
+}
+
+// parseByteRange - start and inclusive end offset of START-END, START- or -N within the file size, the
+// end is before the start when the range is empty
+func parseByteRange(byteRange string, size int64) (int64, int64, error) {
+	invalid := fmt.Errorf("invalid byte range %q, use START-END, START- or -N", byteRange)
+	parts := strings.SplitN(byteRange, "-", 2)
+	if len(parts) != 2 || (parts[0] == "" && parts[1] == "") {
+		return 0, 0, invalid
+	}
+
+	if parts[0] == "" {
+		last, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || last < 0 {
+			return 0, 0, invalid
+		}
+		start := size - last
+		if start < 0 {
+			start = 0
+		}
+		return start, size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, invalid
+	}
+	end := size - 1
+	if parts[1] != "" {
+		if end, err = strconv.ParseInt(parts[1], 10, 64); err != nil || end < start {
+			return 0, 0, invalid
+		}
+	}
+	if start >= size {
+		if size == 0 {
+			return 0, -1, nil
+		}
+		return 0, 0, fmt.Errorf("byte range %q starts after the end of the file, size: %d", byteRange, size)
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end, nil
+}
+
+// headWriter - passes the content through up to the given number of lines
+type headWriter struct {
+	writer io.Writer
+	lines  int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	for i, b := range p {
+		if b != '\n' {
+			continue
+		}
+		w.lines--
+		if w.lines == 0 {
+			n, err := w.writer.Write(p[:i+1])
+			if err == nil {
+				err = errHeadDone
+			}
+			return n, err
+		}
+	}
+	return w.writer.Write(p)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		byteRange string
+		size      int64
+		wantStart int64
+		wantEnd   int64
+		wantErr   bool
+	}{
+		{"0-9", 100, 0, 9, false},
+		{"10-", 100, 10, 99, false},
+		{"-10", 100, 90, 99, false},
+		{"-200", 100, 0, 99, false},
+		{"-0", 100, 100, 99, false},
+		{"90-200", 100, 90, 99, false},
+		{"5-5", 100, 5, 5, false},
+		{"0-", 0, 0, -1, false},
+		{"-10", 0, 0, -1, false},
+		{"100-", 100, 0, 0, true},
+		{"9-5", 100, 0, 0, true},
+		{"-", 100, 0, 0, true},
+		{"10", 100, 0, 0, true},
+		{"a-b", 100, 0, 0, true},
+		{"--5", 100, 0, 0, true},
+		{"-5-", 100, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		start, end, err := parseByteRange(test.byteRange, test.size)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseByteRange(%q, %d) expected error", test.byteRange, test.size)
+			}
+			continue
+		}
+		if err != nil || start != test.wantStart || end != test.wantEnd {
+			t.Errorf("parseByteRange(%q, %d) = %d, %d, %v, want %d, %d", test.byteRange, test.size, start, end, err, test.wantStart, test.wantEnd)
+		}
+	}
+}
+
+func TestHeadWriter(t *testing.T) {
+	tests := []struct {
+		chunks   []string
+		lines    int
+		want     string
+		wantDone bool
+	}{
+		{[]string{"a\nb\nc\n"}, 2, "a\nb\n", true},
+		{[]string{"a\nb\nc\n"}, 3, "a\nb\nc\n", true},
+		{[]string{"a\nb\n"}, 5, "a\nb\n", false},
+		{[]string{"a\nb", "c\nd\n"}, 2, "a\nbc\n", true},
+		{[]string{"a", "b", "\n", "c\n"}, 1, "ab\n", true},
+		{[]string{"no newline"}, 1, "no newline", false},
+		{[]string{"", "a\n"}, 1, "a\n", true},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		w := &headWriter{writer: &out, lines: test.lines}
+		done := false
+		for _, chunk := range test.chunks {
+			if _, err := w.Write([]byte(chunk)); err != nil {
+				if err != errHeadDone {
+					t.Fatalf("headWriter.Write(%q) unexpected error: %v", chunk, err)
+				}
+				done = true
+				break
+			}
+		}
+		if out.String() != test.want || done != test.wantDone {
+			t.Errorf("headWriter(%q, %d) wrote %q, done %t, want %q, done %t", test.chunks, test.lines, out.String(), done, test.want, test.wantDone)
+		}
+	}
+}
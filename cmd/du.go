@@ -0,0 +1,176 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/util"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sort orders of du
+const (
+	duSortPath  = "path"
+	duSortSize  = "size"
+	duSortFiles = "files"
+)
+
+var (
+	topOpt         int
+	duSortOpt      string
+	byExtensionOpt bool
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du <remote-folder>",
+	Short: "Show the size and file count of folders",
+	Long: `Show the total size and number of files of every folder under the remote folder, including their sub
+directories, followed by the total of the remote folder`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithAutoLogin(cmd, args, duCommand)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+	duCmd.Flags().IntVar(&depthOpt, "depth", 0, "Show only the number of folder levels, 0 shows every level")
+	duCmd.Flags().IntVar(&topOpt, "top", 0, "Show only the N folders first in the sort order, e.g. the largest with --sort size")
+	duCmd.Flags().StringVar(&duSortOpt, "sort", duSortPath, "Sort the folders by path, size (largest first) or files (most first)")
+	duCmd.Flags().BoolVar(&reverseOpt, "reverse", false, "Reverse the sort order")
+	duCmd.Flags().BoolVar(&humanOpt, "human", false, "Show sizes in SI units instead of bytes")
+	duCmd.Flags().BoolVar(&byExtensionOpt, "by-extension", false, "Add the size and file count by file extension")
+	duCmd.Flags().Int64Var(&limitOpt, "limit", 100, "Number of items listed per page")
+}
+
+// extensionUsage - the files of one extension
+type extensionUsage struct {
+	extension string
+	files     int
+	size      int64
+}
+
+func duCommand(cmd *cobra.Command, args []string) error {
+	root := "/"
+	if len(args) == 1 {
+		root = trimRemoteFolder(strings.TrimSpace(args[0]))
+	}
+
+	switch duSortOpt {
+	case duSortPath, duSortSize, duSortFiles:
+	default:
+		return fmt.Errorf("invalid sort %q, use path, size or files", duSortOpt)
+	}
+	if depthOpt < 0 || topOpt < 0 {
+		return fmt.Errorf("--depth and --top must not be negative")
+	}
+	if err := validateLimitOpt(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	tree, err := buildTree(root, &itemFilter{})
+	if err != nil {
+		return err
+	}
+
+	folders, usages := diskUsage(tree, depthOpt)
+	sort.SliceStable(folders, func(i, j int) bool {
+		a, b := folders[i], folders[j]
+		if reverseOpt {
+			a, b = b, a
+		}
+		switch duSortOpt {
+		case duSortSize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case duSortFiles:
+			if a.Files != b.Files {
+				return a.Files > b.Files
+			}
+		}
+		return a.Path < b.Path
+	})
+	if topOpt > 0 && len(folders) > topOpt {
+		folders = folders[:topOpt]
+	}
+
+	for _, folder := range folders {
+		fmt.Printf("%12s  %8d  %s\n", formatDiskUsage(folder.Size), folder.Files, folder.Path)
+	}
+	fmt.Printf("%12s  %8d  %s\n", formatDiskUsage(tree.Size), tree.Files, tree.Path)
+
+	if byExtensionOpt {
+		fmt.Printf("\n%12s  %8s  %s\n", "size", "files", "extension")
+		for _, usage := range usages {
+			fmt.Printf("%12s  %8d  %s\n", formatDiskUsage(usage.size), usage.files, usage.extension)
+		}
+	}
+	return nil
+}
+
+// diskUsage - the folders down to the depth, every level when 0, and the usage of every file extension
+// with the largest first, the folder totals include the files of every level
+func diskUsage(tree *treeNode, depth int) ([]*treeNode, []*extensionUsage) {
+	var folders []*treeNode
+	extensions := map[string]*extensionUsage{}
+	var visit func(node *treeNode, level int)
+	visit = func(node *treeNode, level int) {
+		for _, child := range node.Children {
+			if child.Kind != "folder" {
+				ext := strings.ToLower(path.Ext(child.Name))
+				if ext == "" {
+					ext = "(none)"
+				}
+				if extensions[ext] == nil {
+					extensions[ext] = &extensionUsage{extension: ext}
+				}
+				extensions[ext].files++
+				extensions[ext].size += child.Size
+				continue
+			}
+			if depth == 0 || level <= depth {
+				folders = append(folders, child)
+			}
+			visit(child, level+1)
+		}
+	}
+	visit(tree, 1)
+
+	usages := make([]*extensionUsage, 0, len(extensions))
+	for _, usage := range extensions {
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].size != usages[j].size {
+			return usages[i].size > usages[j].size
+		}
+		return usages[i].extension < usages[j].extension
+	})
+	return folders, usages
+}
+
+// formatDiskUsage - the size in SI units with --human, otherwise in bytes
+func formatDiskUsage(size int64) string {
+	if humanOpt {
+		return util.ByteCountSI(size)
+	}
+	return strconv.FormatInt(size, 10)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/veeva/vvfst/model"
+	"testing"
+)
+
+func TestDiskUsageDepth(t *testing.T) {
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{0, []string{"/inbox 8 2", "/inbox/2020 3 1", "/inbox/empty 0 0"}},
+		{1, []string{"/inbox 8 2"}},
+		{2, []string{"/inbox 8 2", "/inbox/2020 3 1", "/inbox/empty 0 0"}},
+	}
+
+	tree := newTestTree()
+	for _, test := range tests {
+		folders, _ := diskUsage(tree, test.depth)
+		var got []string
+		for _, folder := range folders {
+			got = append(got, fmt.Sprintf("%s %d %d", folder.Path, folder.Size, folder.Files))
+		}
+		if !equalPaths(got, test.want) {
+			t.Errorf("diskUsage(tree, %d) folders = %q, want %q", test.depth, got, test.want)
+		}
+	}
+}
+
+func TestDiskUsageByExtension(t *testing.T) {
+	tree := newFolderNode("/")
+	tree.add("inbox/a.xml", &model.Item{Path: "/inbox/a.xml", Name: "a.xml", Kind: "file", Size: 3})
+	tree.add("inbox/2020/B.XML", &model.Item{Path: "/inbox/2020/B.XML", Name: "B.XML", Kind: "file", Size: 10})
+	tree.add("c.csv", &model.Item{Path: "/c.csv", Name: "c.csv", Kind: "file", Size: 4})
+	tree.add("d.txt", &model.Item{Path: "/d.txt", Name: "d.txt", Kind: "file", Size: 4})
+	tree.add("inbox/README", &model.Item{Path: "/inbox/README", Name: "README", Kind: "file", Size: 1})
+	tree.summarize()
+
+	want := []string{".xml 13 2", ".csv 4 1", ".txt 4 1", "(none) 1 1"}
+	for _, depth := range []int{0, 1} {
+		_, usages := diskUsage(tree, depth)
+		var got []string
+		for _, usage := range usages {
+			got = append(got, fmt.Sprintf("%s %d %d", usage.extension, usage.size, usage.files))
+		}
+		if !equalPaths(got, want) {
+			t.Errorf("diskUsage(tree, %d) extensions = %q, want %q", depth, got, want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ and limitations under the License.
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/eiannone/keyboard"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/veeva/vvfst/net"
 	"github.com/veeva/vvfst/util"
 	"github.com/veeva/vvfst/vlog"
+	"golang.org/x/crypto/ssh/terminal"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -38,6 +40,9 @@ var (
 	csvFormatOpt bool
 	overwriteOpt bool
 	limitOpt     int64
+	allOpt       bool
+	maxItemsOpt  int64
+	pagerOpt     bool
 	threadCnt    int
 	timoutSec    int
 )
@@ -64,7 +69,11 @@ var mkdirCmd = &cobra.Command{
 var uploadCmd = &cobra.Command{
 	Use:   "upload <local-file/folder> <remote-file/folder>",
 	Short: "Copy a file or folder to remote directory",
-	Long:  `Uploading a single file or all files from a folder`,
+	Long: `Uploading a single file or all files from a folder.  Use - as <local-file> to upload from stdin up to the
+multipart threshold, e.g.
+  pg_dump mydb | gzip | vvfst upload - /exports/db.gz
+Use --from-archive to upload the content of a zip or tar archive without extracting it, e.g.
+  vvfst upload --from-archive bundle.zip /inbox/`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runWithAutoLogin(cmd, args, uploadCommand)
 	},
@@ -72,7 +81,9 @@ var uploadCmd = &cobra.Command{
 var downloadCmd = &cobra.Command{
 	Use:   "download <remote-file/folder> <local-file/folder>",
 	Short: "Download folder/files remote",
-	Long:  `Download folder/files from remote staging folder to current folder `,
+	Long: `Download folder/files from remote staging folder to current folder 
+
+Use - as the local file to write the content of a remote file to stdout, logs and progress are written to stderr`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runWithAutoLogin(cmd, args, downloadCommand)
 	},
@@ -129,6 +140,15 @@ func init() {
 	lsCmd.Flags().BoolVarP(&recursiveOpt, "recursive", "r", false, "Enable recursive mode to list all sub directories")
 	lsCmd.Flags().BoolVarP(&csvFormatOpt, "csvFormat", "c", false, "Export content list as csv file.")
 	lsCmd.Flags().Int64VarP(&limitOpt, "limit", "l", 100, "Limit number of items")
+	lsCmd.Flags().BoolVar(&longOpt, "long", false, "Long listing with the exact size, modified time, MD5 and full path")
+	lsCmd.Flags().BoolVar(&humanOpt, "human", false, "Show sizes in SI units (default of the table), --human=false shows bytes, the long listing adds the SI size")
+	lsCmd.Flags().BoolVar(&utcOpt, "utc", false, "Show the modified time in UTC instead of the local time")
+	lsCmd.Flags().StringVar(&sortOpt, "sort", "", "Sort the listed items by name, size (largest first) or time (newest first)")
+	lsCmd.Flags().BoolVar(&reverseOpt, "reverse", false, "Reverse the sort order")
+	lsCmd.Flags().BoolVar(&allOpt, "all", false, "List every page of the listing")
+	lsCmd.Flags().Int64Var(&maxItemsOpt, "max-items", 0, "Stop after listing the number of items, following pages as needed")
+	lsCmd.Flags().BoolVar(&pagerOpt, "pager", false, "Wait for the space-bar before listing the next page, only on a terminal")
+	lsCmd.Flags().StringVarP(&outputOpt, "output", "o", outputTable, "Output format: table, json, ndjson, csv or paths")
 
 	// Mkdir
 	rootCmd.AddCommand(mkdirCmd)
@@ -138,11 +158,29 @@ func init() {
 	rootCmd.AddCommand(uploadCmd)
 	uploadCmd.Flags().BoolVarP(&overwriteOpt, "overwrite", "o", false, "Enable overwrite to overwrite if file/folder exists")
 	uploadCmd.Flags().IntVarP(&threadCnt, "threadCount", "t", 1, "Number of concurrent thread to upload")
+	uploadCmd.Flags().StringVar(&reportOpt, "report", "", "Write a transfer report of every file, out.json or out.csv")
+	uploadCmd.Flags().BoolVar(&failFastOpt, "fail-fast", false, "Stop at the first failure instead of continuing with remaining files")
+	uploadCmd.Flags().BoolVar(&followSymlinksOpt, "follow-symlinks", false, "Upload the target of symbolic links to files and folders, loops are detected and skipped")
+	uploadCmd.Flags().BoolVar(&skipSymlinksOpt, "skip-symlinks", false, "Skip symbolic links and report them (default)")
+	uploadCmd.Flags().BoolVar(&fromArchiveOpt, "from-archive", false, "Upload the entries of a .zip, .tar, .tar.gz or .tgz archive into the remote folder without extracting it")
+	uploadCmd.Flags().String("part-size", "", "Multipart part size such as 10MiB (MB is read as MiB), or auto to tune it from throughput and errors (default by file size)")
+	uploadCmd.Flags().String("multipart-threshold", "", "Upload files larger than this size in parts (default 50MiB)")
+	addFilterFlags(uploadCmd)
 
 	// Download
 	rootCmd.AddCommand(downloadCmd)
 	downloadCmd.Flags().BoolVarP(&recursiveOpt, "recursive", "r", false, "Enable recursive mode to download all sub directories")
 	downloadCmd.Flags().IntVarP(&threadCnt, "threadCount", "t", 1, "Number of concurrent thread to download")
+	downloadCmd.Flags().StringVar(&reportOpt, "report", "", "Write a transfer report of every file, out.json or out.csv")
+	downloadCmd.Flags().BoolVar(&failFastOpt, "fail-fast", false, "Stop at the first failure instead of continuing with remaining files")
+	addFilterFlags(downloadCmd)
+	downloadCmd.Flags().Int64Var(&limitOpt, "limit", 100, "Number of items listed per page")
+	downloadCmd.Flags().BoolVar(&noClobberOpt, "no-clobber", false, "Skip files which exist locally instead of replacing them")
+	downloadCmd.Flags().BoolVar(&backupOpt, "backup", false, "Rename an existing local file to <name>~ before replacing it")
+	downloadCmd.Flags().BoolVar(&skipExistingOpt, "skip-existing", false, "Skip files which exist locally with the same size")
+	downloadCmd.Flags().BoolVar(&updateOpt, "update", false, "Download only files whose size, modified time and MD5 differ from the local file")
+	downloadCmd.Flags().String("segment-size", "", "Size of the byte ranges a large file is downloaded in concurrently, MB is read as MiB (default 50MiB)")
+	downloadCmd.Flags().String("segment-threshold", "", "Download files of at least this size in concurrent byte ranges using --threadCount connections (default 100MiB)")
 
 	// Move
 	rootCmd.AddCommand(moveCmd)
@@ -164,14 +202,91 @@ func init() {
 	jobListCmd.Flags().IntVarP(&timoutSec, "timoutSeconds", "T", 60, "How long job status to be checked")
 }
 
-func listCommand(_ *cobra.Command, args []string) error {
+// errListingDone - stops the listing once --max-items are listed
+var errListingDone = errors.New("listing done")
+
+// listItems - write every item of the listing in a structured format to stdout, all pages are listed
+// without prompting up to --max-items and logs are written to stderr
+func listItems(itemPath, format string) error {
+	vlog.SetOutput(os.Stderr)
+	stdout := bufio.NewWriter(os.Stdout)
+	writer, err := newItemWriter(format, stdout)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	var listed []*model.Item
+	err = api.WalkItems(itemPath, limitOpt, recursiveOpt, func(item *model.Item) error {
+		if maxItemsOpt > 0 && count >= maxItemsOpt {
+			return errListingDone
+		}
+		count++
+		if sortOpt != "" {
+			listed = append(listed, item)
+			return nil
+		}
+		return writer.write(item)
+	})
+	if err == errListingDone {
+		err = nil
+	}
+
+	sortItems(listed, sortOpt, reverseOpt)
+	for _, item := range listed {
+		if err != nil {
+			break
+		}
+		err = writer.write(item)
+	}
+	if err == nil {
+		err = writer.close()
+	}
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// validateLimitOpt - the page size of a listing
+func validateLimitOpt() error {
+	if limitOpt < 0 || limitOpt > 1000 {
+		return fmt.Errorf("limit must be between 0 and 1000")
+	}
+	return nil
+}
+
+func listCommand(cmd *cobra.Command, args []string) error {
 	itemPath := "/"
 	if len(args) == 1 {
 		itemPath = strings.TrimSpace(args[0])
 	}
 
-	if limitOpt < 0 || limitOpt > 1000 {
-		return fmt.Errorf("limit must be between 0 and 1000")
+	if err := validateLimitOpt(); err != nil {
+		return err
+	}
+	if err := validateOutputOpt(); err != nil {
+		return err
+	}
+	if maxItemsOpt < 0 {
+		return fmt.Errorf("--max-items must not be negative")
+	}
+	if err := validateSortOpt(); err != nil {
+		return err
+	}
+	if sortOpt != "" && pagerOpt {
+		return fmt.Errorf("--sort cannot be used with --pager, the items of every page are sorted together")
+	}
+	// the table shows SI sizes and the long listing bytes unless --human is given
+	if !cmd.Flags().Changed("human") {
+		humanOpt = !longOpt
+	}
+
+	if outputOpt != outputTable {
+		if csvFormatOpt {
+			return fmt.Errorf("--csvFormat and --output cannot be used together")
+		}
+		return listItems(itemPath, outputOpt)
 	}
 
 	if csvFormatOpt {
@@ -198,13 +313,24 @@ func listCommand(_ *cobra.Command, args []string) error {
 				}
 
 				vlog.Infof("Downloading reports %s", reportPath)
-				api.DownloadSingleFile(downloadItem)
+				return api.DownloadSingleFile(downloadItem).Err
 			}
 		}
 
 		return nil
 	}
 
+	// a pager needs a keyboard, without one every page is listed
+	pager := pagerOpt && isInteractive()
+	if pagerOpt && !pager {
+		vlog.Debugf("Not running in a terminal, listing every page without the pager")
+	}
+	if allOpt || maxItemsOpt > 0 || (pagerOpt && !pager) {
+		return listTable(itemPath)
+	}
+
+	var table *tableItemWriter
+	var listed []*model.Item
 	firstPageItemPath := itemPath
 	nextPageURL := ""
 	nextPage := false
@@ -220,20 +346,71 @@ func listCommand(_ *cobra.Command, args []string) error {
 			return errors.Errorf("No items found")
 		}
 
-		fmt.Printf("listing: %s\n", itemPath)
-		fmt.Printf("%-6.6s  %-50.50s  %s\n", "kind", "path", "size")
-		fmt.Printf("====================================================================\n")
+		if table == nil {
+			table = newTableItemWriter(os.Stdout, itemPath, longOpt, humanOpt, utcOpt)
+		}
 		for _, item := range itemsRestResult.Data {
-			s := util.FixedWidth(item.Path, 50, true)
-			fmt.Printf("%-6.6s  %-50.50s  %s\n", item.Kind, s, util.ByteCountSI(item.Size))
+			// a sorted listing is printed once every page is listed
+			if sortOpt != "" {
+				listed = append(listed, item)
+				continue
+			}
+			if err := table.write(item); err != nil {
+				return err
+			}
 		}
 
-		if itemsRestResult.ResponseDetails != nil {
+		if itemsRestResult.ResponseDetails != nil && itemsRestResult.ResponseDetails.NextPage != "" {
 			nextPageURL = itemsRestResult.ResponseDetails.NextPage
-			nextPage = continueNextPage()
+			if pager {
+				nextPage = continueNextPage()
+			} else {
+				vlog.Infof("More items are available, use --all, --max-items or --pager to list them")
+			}
 		}
 	}
 
+	return writeSortedTable(table, listed)
+}
+
+// listTable - print every page of the listing as a table up to --max-items, a cached listing is used
+// when available
+func listTable(itemPath string) error {
+	var table *tableItemWriter
+	var listed []*model.Item
+	var count int64
+	err := api.WalkItems(itemPath, limitOpt, recursiveOpt, func(item *model.Item) error {
+		if maxItemsOpt > 0 && count >= maxItemsOpt {
+			return errListingDone
+		}
+		count++
+		if table == nil {
+			table = newTableItemWriter(os.Stdout, itemPath, longOpt, humanOpt, utcOpt)
+		}
+		if sortOpt != "" {
+			listed = append(listed, item)
+			return nil
+		}
+		return table.write(item)
+	})
+	if err != nil && err != errListingDone {
+		return err
+	}
+
+	if table == nil {
+		table = newTableItemWriter(os.Stdout, itemPath, longOpt, humanOpt, utcOpt)
+	}
+	return writeSortedTable(table, listed)
+}
+
+// writeSortedTable - print the items collected for --sort once the listing is complete
+func writeSortedTable(table *tableItemWriter, listed []*model.Item) error {
+	sortItems(listed, sortOpt, reverseOpt)
+	for _, item := range listed {
+		if err := table.write(item); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -243,9 +420,7 @@ func mkdirCommand(_ *cobra.Command, args []string) error {
 	}
 
 	remoteItem := strings.TrimSpace(args[0])
-	api.CreateFolder(remoteItem, overwriteOpt, true)
-
-	return nil
+	return api.CreateFolder(remoteItem, overwriteOpt, true).Err
 }
 
 func mvCommand(_ *cobra.Command, args []string) error {
@@ -263,33 +438,7 @@ func mvCommand(_ *cobra.Command, args []string) error {
 		destName = srcName
 	}
 
-	params := map[string]string{
-		"parent":    destParent,
-		"name":      destName,
-		"overwrite": strconv.FormatBool(overwriteOpt),
-	}
-
-	var jobRestResult *model.JobRestResult
-	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
-	resp, err := req.SetResult(&jobRestResult).
-		SetHeader("Content-Type", "application/x-www-form-urlencoded").
-		SetFormData(params).
-		Put(fmt.Sprintf("/services/file_staging/items%s", srcRemoteItem))
-
-	if err != nil {
-		return errors.Errorf("Failed to connect: %v", err)
-	}
-
-	if len(jobRestResult.Errors) != 0 {
-		return errors.New(net.FormatRestResultError("", jobRestResult.Errors[0]))
-	}
-
-	net.LogTime("mv submitted successfully, waiting for job completion", resp)
-
-	_, err = api.WaitForJobCompletion(jobRestResult.Data.JobID,
-		fmt.Sprintf("%s moved to %s successfully", srcRemoteItem, destRemoteItem), config.JobTimeoutSeconds)
-
-	return err
+	return api.MoveItem(srcRemoteItem, destParent, destName, overwriteOpt)
 }
 
 func rmCommand(_ *cobra.Command, args []string) error {
@@ -298,28 +447,10 @@ func rmCommand(_ *cobra.Command, args []string) error {
 	}
 
 	remoteItem := strings.TrimSpace(args[0])
-
-	var jobRestResult *model.JobRestResult
-	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
-	resp, err := req.SetResult(&jobRestResult).
-		SetQueryParam("recursive", strconv.FormatBool(recursiveOpt)).
-		Delete(fmt.Sprintf("/services/file_staging/items%s", remoteItem))
-
-	if err != nil {
-		return errors.Errorf("Failed to connect: %v", err)
-	}
-
-	if len(jobRestResult.Errors) != 0 {
-		return errors.New(net.FormatRestResultError("", jobRestResult.Errors[0]))
-	}
-
-	net.LogTime("rm submitted successfully, waiting for job completion", resp)
-
-	_, err = api.WaitForJobCompletion(jobRestResult.Data.JobID, fmt.Sprintf("%s removed successfully", remoteItem), config.JobTimeoutSeconds)
-	return err
+	return api.DeleteItem(remoteItem, recursiveOpt)
 }
 
-func uploadCommand(_ *cobra.Command, args []string) error {
+func uploadCommand(cmd *cobra.Command, args []string) (err error) {
 	if len(args) != 2 {
 		return fmt.Errorf("missing required args <local-folder/file> and/or <remote-folder/file>")
 	}
@@ -327,6 +458,33 @@ func uploadCommand(_ *cobra.Command, args []string) error {
 	localItem := strings.TrimSpace(args[0])
 	remoteItem := strings.TrimSpace(args[1])
 
+	if err := validateReportOpt(); err != nil {
+		return err
+	}
+	if err := config.ValidatePartSize(); err != nil {
+		return err
+	}
+	if threadCnt < 1 {
+		return fmt.Errorf("threadCount must be at least 1")
+	}
+	cmd.SilenceUsage = true
+	report := newTransferReport()
+	defer func() {
+		err = report.writeReport(err)
+	}()
+
+	if fromArchiveOpt {
+		return uploadArchive(localItem, remoteItem, report)
+	}
+
+	if localItem == "-" {
+		if util.EndWithFileSeparator(remoteItem) {
+			return fmt.Errorf("must specify a remote file name when uploading from stdin")
+		}
+		report.add(api.UploadStream(os.Stdin, remoteItem, overwriteOpt))
+		return report.summarize("Upload")
+	}
+
 	localItemStat, err := os.Stat(localItem)
 	if err != nil {
 		return fmt.Errorf("%s not found", localItem)
@@ -336,14 +494,41 @@ func uploadCommand(_ *cobra.Command, args []string) error {
 		if util.EndWithFileSeparator(remoteItem) {
 			remoteItem = remoteItem + localItemStat.Name()
 		}
-		api.UploadSingleFile(&model.UploadItem{RemotePath: remoteItem, LocalPath: localItem}, overwriteOpt)
-		return nil
+		report.add(api.UploadSingleFile(&model.UploadItem{RemotePath: remoteItem, LocalPath: localItem}, overwriteOpt))
+		return report.summarize("Upload")
+	}
+
+	// a named pipe such as <(pg_dump mydb) is uploaded as a stream
+	if localItemStat.Mode()&os.ModeNamedPipe != 0 {
+		if util.EndWithFileSeparator(remoteItem) {
+			return fmt.Errorf("must specify a remote file name when uploading from a named pipe")
+		}
+		pipe, err := os.Open(localItem)
+		if err != nil {
+			return err
+		}
+		defer pipe.Close()
+		report.add(api.UploadStream(pipe, remoteItem, overwriteOpt))
+		return report.summarize("Upload")
+	}
+
+	if !localItemStat.IsDir() {
+		return fmt.Errorf("%s is a %s, only files and folders can be uploaded", localItem, describeFileType(localItemStat.Mode()))
+	}
+
+	if followSymlinksOpt && skipSymlinksOpt {
+		return fmt.Errorf("--follow-symlinks and --skip-symlinks cannot be used together")
 	}
 
 	if util.EndWithFileSeparator(remoteItem) {
 		remoteItem = util.TrimLastChar(remoteItem)
 	}
 
+	filter, err := buildItemFilter(filepath.Join(localItem, ignoreFilename))
+	if err != nil {
+		return err
+	}
+
 	var wg sync.WaitGroup
 	ch := make(chan *model.UploadItem, threadCnt)
 
@@ -355,38 +540,88 @@ func uploadCommand(_ *cobra.Command, args []string) error {
 			defer wg.Done()
 
 			for item := range ch {
-				api.UploadSingleFile(item, overwriteOpt)
+				if report.stopped() {
+					continue
+				}
+				report.add(api.UploadSingleFile(item, overwriteOpt))
 			}
 		}()
 	}
 
-	err = filepath.Walk(localItem, func(path string, info os.FileInfo, e error) error {
-		if e != nil {
-			return e
+	// the walk runs in a single goroutine and the created folders need no lock
+	folders := newFolderCreator(filter.selectsFiles(), func(relPath string) bool {
+		result := api.CreateFolder(remoteItemPath(remoteItem, relPath), true, false)
+		if result.Status == model.TransferFailed {
+			result.LocalPath = filepath.Join(localItem, filepath.FromSlash(relPath))
+			report.add(result)
+			return false
 		}
+		return true
+	})
 
-		// check if it is a regular file (not dir)
-		remotePath1 := strings.Replace(path, localItem, "", 1)
-		remotePath1 = strings.ReplaceAll(remotePath1, string(os.PathSeparator), "/")
-		remotePath := remoteItem + remotePath1
-		if info.Mode().IsRegular() {
-			uploadItem := model.UploadItem{RemotePath: remotePath, LocalPath: path}
-			ch <- &uploadItem
-		}
+	walker := &localWalker{
+		followSymlinks: followSymlinksOpt,
+		visitDir: func(path, relPath string, info os.FileInfo) error {
+			if report.stopped() {
+				return errTransferStopped
+			}
 
-		if info.Mode().IsDir() {
-			api.CreateFolder(remotePath, true, false)
-		}
-		return nil
-	})
+			if relPath != "." && filter.skipDir(relPath) {
+				vlog.Debugf("Excluded folder: %s", path)
+				return filepath.SkipDir
+			}
+
+			if !folders.visitDir(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		},
+		visitFile: func(path, relPath string, info os.FileInfo) error {
+			if report.stopped() {
+				return errTransferStopped
+			}
+
+			if !filter.acceptFile(relPath, info.Size(), info.ModTime()) {
+				vlog.Debugf("Excluded file: %s", path)
+				return nil
+			}
+
+			if !folders.folder(parentRelPath(relPath)) {
+				return nil
+			}
+			ch <- &model.UploadItem{RemotePath: remoteItemPath(remoteItem, relPath), LocalPath: path}
+			return nil
+		},
+		skip: func(path, relPath, reason string, err error) {
+			vlog.Warnf("Skipped %s: %v", path, err)
+			report.add(newSkippedResult(path, remoteItemPath(remoteItem, relPath), reason, err))
+		},
+		fail: func(path, relPath string, err error) {
+			vlog.Errorf("Failed to read %s: %v", path, err)
+			report.add(newFailedResult(path, remoteItemPath(remoteItem, relPath), err))
+		},
+		excluded: filter.excludedPath,
+	}
+	err = walker.walk(localItem)
 
 	close(ch)
 	wg.Wait()
 
-	return err
+	if err != nil && err != errTransferStopped {
+		return err
+	}
+	return report.summarize("Upload")
+}
+
+// remoteItemPath - remote path of the item relative to the uploaded folder
+func remoteItemPath(remoteItem, relPath string) string {
+	if relPath == "." {
+		return remoteItem
+	}
+	return remoteItem + "/" + relPath
 }
 
-func downloadCommand(_ *cobra.Command, args []string) error {
+func downloadCommand(cmd *cobra.Command, args []string) error {
 	if len(args) != 2 {
 		return fmt.Errorf("missing required args <remote-folder/file> and/or <local-folder/file>")
 	}
@@ -394,44 +629,49 @@ func downloadCommand(_ *cobra.Command, args []string) error {
 	remoteItem := strings.TrimSpace(args[0])
 	localItem := strings.TrimSpace(args[1])
 
-	firstPageItemPath := remoteItem
-	nextPageURL := ""
-	nextPage := false
-	for ok := true; ok; ok = nextPage {
-		itemsRestResult, err := api.ListPage(firstPageItemPath, nextPageURL, limitOpt, recursiveOpt, false)
-		nextPage = false
-
-		if err != nil {
-			return err
-		}
-
-		if itemsRestResult == nil {
-			return errors.Errorf("No items found")
+	if localItem == stdoutPath {
+		vlog.SetOutput(os.Stderr)
+		if recursiveOpt {
+			return fmt.Errorf("--recursive cannot be used when downloading to stdout")
 		}
+		cmd.SilenceUsage = true
+		return writeRemoteFile(remoteItem, "", 0, true)
+	}
 
-		var downloadItems []*model.DownloadItem
-		for _, item := range itemsRestResult.Data {
-			if item.Kind == "folder" {
-				continue
-			}
-
-			localPath1 := strings.Replace(item.Path, remoteItem, "", 1)
-			localPath := filepath.Join(localItem, localPath1)
+	if err := validateReportOpt(); err != nil {
+		return err
+	}
+	if err := config.ValidateSegmentSize(); err != nil {
+		return err
+	}
+	if err := validateIncrementalOpt(); err != nil {
+		return err
+	}
+	if err := validateLimitOpt(); err != nil {
+		return err
+	}
+	if threadCnt < 1 {
+		return fmt.Errorf("threadCount must be at least 1")
+	}
+	filter, err := buildItemFilter("")
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	return downloadItems(remoteItem, recursiveOpt, func(item *model.Item) string {
+		localPath1 := strings.Replace(item.Path, remoteItem, "", 1)
+		localPath := filepath.Join(localItem, localPath1)
 
-			downloadItem := &model.DownloadItem{RemotePath: item.Path, Size: item.Size, LocalPath: localPath}
-			downloadItems = append(downloadItems, downloadItem)
-			//DownloadSingleFile(downloadItem)
+		relPath := strings.TrimPrefix(localPath1, "/")
+		if relPath == "" {
+			relPath = item.Name
 		}
-
-		downloadInParallel(downloadItems)
-
-		if itemsRestResult.ResponseDetails != nil {
-			nextPageURL = itemsRestResult.ResponseDetails.NextPage
-			nextPage = true
+		if !filter.acceptItem(relPath, item) {
+			vlog.Debugf("Excluded file: %s", item.Path)
+			return ""
 		}
-	}
-
-	return nil
+		return localPath
+	})
 }
 
 func mlistCommand(_ *cobra.Command, _ []string) error {
@@ -474,23 +714,7 @@ func mrmCommand(_ *cobra.Command, args []string) error {
 		return errors.Errorf("No upload session available for file %s", remoteItem)
 	}
 
-	req := net.InitRestClient(config.EnableDebug).BuildRestRequest(true)
-	var restResult model.RestResult
-	resp, err := req.
-		SetResult(&restResult).
-		Delete(fmt.Sprintf("/services/file_staging/upload/%s", uploadSession.UploadSessionID))
-
-	if err != nil {
-		return errors.Errorf("Failed to connect: %v", err)
-	}
-
-	if len(restResult.Errors) != 0 {
-		return errors.Errorf(net.FormatRestResultError(remoteItem, restResult.Errors[0]))
-	}
-
-	net.LogTime(fmt.Sprintf("Deleted upload session for %s", remoteItem), resp)
-
-	return nil
+	return api.MultipartUploadDelete(uploadSession)
 }
 
 func jobListCommand(_ *cobra.Command, _ []string) error {
@@ -548,11 +772,62 @@ func jobListCommand(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func downloadInParallel(items []*model.DownloadItem) {
-	var wg sync.WaitGroup
+// downloadItems - download the files listed under the remote path, localPath returns the local path of
+// a listed file or an empty path to leave the file out
+func downloadItems(remotePath string, recursive bool, localPath func(item *model.Item) string) (err error) {
+	report := newTransferReport()
+	defer func() {
+		err = report.writeReport(err)
+	}()
+	defer handleInterrupt(func() {
+		report.cancel()
+		api.AbortDownloads()
+	})()
+
+	progress := api.StartProgress()
 	ch := make(chan *model.DownloadItem, threadCnt)
+	wg := downloadWorkers(ch, report, progress)
+
+	// the listing waits while the workers are busy, so pages are fetched as the queue drains
+	err = api.WalkItems(remotePath, limitOpt, recursive, func(item *model.Item) error {
+		if report.stopped() {
+			return errTransferStopped
+		}
+		if item.Kind == "folder" {
+			return nil
+		}
+
+		itemLocalPath := localPath(item)
+		if itemLocalPath == "" {
+			return nil
+		}
+		progress.Queued(item.Size)
+		ch <- &model.DownloadItem{RemotePath: item.Path, Size: item.Size, MD5: item.MD5,
+			ModifiedDate: item.ModifiedDate, LocalPath: itemLocalPath, Threads: threadCnt, Backup: backupOpt}
+		return nil
+	})
+	progress.Listed()
+
+	close(ch)
+	wg.Wait()
+	progress.Stop()
+
+	if err != nil && err != errTransferStopped {
+		return err
+	}
+	err = report.summarize("Download")
+	if interruptErr := report.interrupted("Download"); interruptErr != nil {
+		return interruptErr
+	}
+	return err
+}
+
+// downloadWorkers - run the worker pool downloading the items of the channel until it is closed
+func downloadWorkers(ch <-chan *model.DownloadItem, report *transferReport, progress *api.Progress) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	// the workers and the byte ranges of large files share the threadCount connections
+	api.SetConnectionLimit(threadCnt)
 
-	// run worker pool
 	for i := threadCnt; i > 0; i-- {
 		wg.Add(1)
 
@@ -560,23 +835,33 @@ func downloadInParallel(items []*model.DownloadItem) {
 			defer wg.Done()
 
 			for item := range ch {
-				api.DownloadSingleFile(item)
+				if report.stopped() {
+					progress.Finished(item.Size)
+					continue
+				}
+				if result := skipDownload(item); result != nil {
+					report.add(result)
+					progress.Finished(item.Size)
+					continue
+				}
+				report.add(api.DownloadSingleFile(item))
+				progress.Finished(item.Size)
 			}
 		}()
 	}
+	return &wg
+}
 
-	for _, i := range items {
-		ch <- i
-	}
-
-	close(ch)
-	wg.Wait()
+// isInteractive - Return true when a user can answer the pager, stdin and stdout are both terminals
+func isInteractive() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
 }
 
 func continueNextPage() bool {
 	keysEvents, err := keyboard.GetKeys(10)
 	if err != nil {
-		panic(err)
+		vlog.Errorf("Failed to read the keyboard, stopping the listing: %v", err)
+		return false
 	}
 	defer func() {
 		_ = keyboard.Close()
@@ -586,7 +871,8 @@ func continueNextPage() bool {
 	for {
 		event := <-keysEvents
 		if event.Err != nil {
-			panic(event.Err)
+			vlog.Errorf("Failed to read the keyboard, stopping the listing: %v", event.Err)
+			return false
 		}
 		if event.Key == keyboard.KeyCtrlC {
 			return false
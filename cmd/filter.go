@@ -0,0 +1,214 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const ignoreFilename = ".vvfstignore"
+
+var (
+	includeOpt   []string
+	excludeOpt   []string
+	minSizeOpt   string
+	maxSizeOpt   string
+	newerThanOpt string
+	olderThanOpt string
+)
+
+// itemFilter - selects files and folders by glob patterns, ignore rules, size and modified time
+type itemFilter struct {
+	includes  []string
+	excludes  []string
+	ignore    util.IgnoreRules
+	minSize   int64
+	maxSize   int64
+	newerThan time.Time
+	olderThan time.Time
+}
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&includeOpt, "include", nil, "Only transfer files matching the glob pattern, repeatable")
+	cmd.Flags().StringArrayVar(&excludeOpt, "exclude", nil, "Skip files and folders matching the glob pattern, repeatable")
+	cmd.Flags().StringVar(&minSizeOpt, "min-size", "", "Skip files smaller than the size, e.g. 10KiB, 5MiB, 1GiB (MB is read as MiB)")
+	cmd.Flags().StringVar(&maxSizeOpt, "max-size", "", "Skip files larger than the size, e.g. 10KiB, 5MiB, 1GiB (MB is read as MiB)")
+	cmd.Flags().StringVar(&newerThanOpt, "newer-than", "", "Only transfer files modified after the timestamp or within the age, e.g. 2020-10-01, 36h, 7d")
+	cmd.Flags().StringVar(&olderThanOpt, "older-than", "", "Only transfer files modified before the timestamp or age, e.g. 2020-10-01, 36h, 7d")
+}
+
+// buildItemFilter - build the filter from the command flags, ignoreFile is optional and may not exist
+func buildItemFilter(ignoreFile string) (*itemFilter, error) {
+	f := &itemFilter{includes: includeOpt, excludes: excludeOpt}
+
+	for _, pattern := range append(append([]string{}, includeOpt...), excludeOpt...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	var err error
+	if ignoreFile != "" {
+		rules, err := util.ParseIgnoreFile(ignoreFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", ignoreFile, err)
+		}
+		// the ignore file itself is not transferred unless a rule negates it with !/.vvfstignore
+		f.ignore = append(util.IgnoreRules{{Pattern: filepath.Base(ignoreFile), Anchored: true}}, rules...)
+	}
+
+	if minSizeOpt != "" {
+		if f.minSize, err = util.ParseByteSize(minSizeOpt); err != nil {
+			return nil, err
+		}
+	}
+
+	if maxSizeOpt != "" {
+		if f.maxSize, err = util.ParseByteSize(maxSizeOpt); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if newerThanOpt != "" {
+		if f.newerThan, err = util.ParseTimeOrAge(newerThanOpt, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if olderThanOpt != "" {
+		if f.olderThan, err = util.ParseTimeOrAge(olderThanOpt, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// selectsFiles - Return true if files are selected by include patterns, size or time, so a folder which is
+// not excluded may still hold no accepted file
+func (f *itemFilter) selectsFiles() bool {
+	return len(f.includes) > 0 || f.minSize > 0 || f.maxSize > 0 || !f.newerThan.IsZero() || !f.olderThan.IsZero()
+}
+
+// skipDir - Return true if the folder and everything under it must be skipped
+func (f *itemFilter) skipDir(relPath string) bool {
+	if f.ignore.Ignored(relPath, true) {
+		return true
+	}
+
+	for _, pattern := range f.excludes {
+		if util.MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipParents - Return true if a folder above the file must be skipped, used with a flat recursive listing
+// where the folders are not visited before their files
+func (f *itemFilter) skipParents(relPath string) bool {
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if f.skipDir(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedPath - Return true if the patterns rule out the item, used for items which are reported without
+// being transferred such as symbolic links and special files
+func (f *itemFilter) excludedPath(relPath string, isDir bool) bool {
+	if isDir {
+		return f.skipDir(relPath)
+	}
+	return !f.acceptName(relPath)
+}
+
+// acceptFile - Return true if the file passes all patterns, size and time filters
+func (f *itemFilter) acceptFile(relPath string, size int64, modTime time.Time) bool {
+	if !f.acceptName(relPath) {
+		return false
+	}
+
+	if f.minSize > 0 && size < f.minSize {
+		return false
+	}
+
+	if f.maxSize > 0 && size > f.maxSize {
+		return false
+	}
+
+	if !f.newerThan.IsZero() && !modTime.After(f.newerThan) {
+		return false
+	}
+
+	if !f.olderThan.IsZero() && !modTime.Before(f.olderThan) {
+		return false
+	}
+
+	return true
+}
+
+// acceptName - Return true if the file passes the ignore rules and the include and exclude patterns
+func (f *itemFilter) acceptName(relPath string) bool {
+	if f.ignore.Ignored(relPath, false) {
+		return false
+	}
+
+	for _, pattern := range f.excludes {
+		if util.MatchGlob(pattern, relPath) {
+			return false
+		}
+	}
+
+	if len(f.includes) > 0 {
+		included := false
+		for _, pattern := range f.includes {
+			if util.MatchGlob(pattern, relPath) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptItem - Return true if the remote file and the folders above it pass the filter, a file without
+// a modified date is skipped when filtering by time
+func (f *itemFilter) acceptItem(relPath string, item *model.Item) bool {
+	if f.skipParents(relPath) {
+		return false
+	}
+
+	var modTime time.Time
+	if item.ModifiedDate != nil {
+		modTime = *item.ModifiedDate
+	} else if !f.newerThan.IsZero() || !f.olderThan.IsZero() {
+		return false
+	}
+	return f.acceptFile(relPath, item.Size, modTime)
+}
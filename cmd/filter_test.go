@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testItemFilter(t *testing.T) *itemFilter {
+	ignore, err := util.ParseIgnore(strings.NewReader("tmp/\n*.bak\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &itemFilter{
+		includes:  []string{"*.xml", "*.csv"},
+		excludes:  []string{"archive"},
+		ignore:    ignore,
+		minSize:   10,
+		maxSize:   1000,
+		newerThan: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSkipParents(t *testing.T) {
+	f := testItemFilter(t)
+	tests := map[string]bool{
+		"a.xml":               false,
+		"inbox/2020/a.xml":    false,
+		"tmp/a.xml":           true,
+		"inbox/tmp/a.xml":     true,
+		"archive/2020/a.xml":  true,
+		"inbox/archive/a.xml": true,
+		"inbox/archive.xml":   false,
+		"inbox/tmp.xml/a.xml": false,
+		"inbox/2020/archive":  false,
+	}
+
+	for relPath, want := range tests {
+		if got := f.skipParents(relPath); got != want {
+			t.Errorf("skipParents(%q) = %t, want %t", relPath, got, want)
+		}
+	}
+}
+
+func TestAcceptItem(t *testing.T) {
+	f := testItemFilter(t)
+	newer := time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2020, 9, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		relPath      string
+		size         int64
+		modifiedDate *time.Time
+		want         bool
+	}{
+		{"inbox/a.xml", 100, &newer, true},
+		{"b.csv", 100, &newer, true},
+		{"inbox/a.txt", 100, &newer, false},
+		{"inbox/a.xml.bak", 100, &newer, false},
+		{"tmp/a.xml", 100, &newer, false},
+		{"archive/2020/a.xml", 100, &newer, false},
+		{"inbox/a.xml", 5, &newer, false},
+		{"inbox/a.xml", 5000, &newer, false},
+		{"inbox/a.xml", 100, &older, false},
+		{"inbox/a.xml", 100, nil, false},
+	}
+
+	for _, test := range tests {
+		item := &model.Item{Path: "/" + test.relPath, Kind: "file", Size: test.size, ModifiedDate: test.modifiedDate}
+		if got := f.acceptItem(test.relPath, item); got != test.want {
+			t.Errorf("acceptItem(%q, size %d, modified %v) = %t, want %t", test.relPath, test.size, test.modifiedDate, got, test.want)
+		}
+	}
+
+	// without a time filter a file without a modified date is accepted
+	f.newerThan = time.Time{}
+	item := &model.Item{Path: "/inbox/a.xml", Kind: "file", Size: 100}
+	if !f.acceptItem("inbox/a.xml", item) {
+		t.Errorf("acceptItem(%q) without modified date = false, want true", item.Path)
+	}
+}
@@ -0,0 +1,344 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/config"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	findNameOpt     string
+	findINameOpt    string
+	findRegexOpt    string
+	findTypeOpt     string
+	findMtimeOpt    string
+	findNewerOpt    string
+	findPrint0Opt   bool
+	findJSONOpt     bool
+	findDeleteOpt   bool
+	findDownloadOpt string
+	yesOpt          bool
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find <remote-folder>",
+	Short: "Search files and folders",
+	Long: `Search the files and folders under the remote folder which match every given predicate and print their
+path, or run an action on them
+
+--mtime N matches items modified N days ago, +N more than N days ago and -N less than N days ago
+--newer matches items modified after the timestamp, the age or the modified time of a remote file`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithAutoLogin(cmd, args, findCommand)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+	findCmd.Flags().StringVar(&findNameOpt, "name", "", "Match the name against the glob pattern")
+	findCmd.Flags().StringVar(&findINameOpt, "iname", "", "Match the name against the glob pattern, ignoring case")
+	findCmd.Flags().StringVar(&findRegexOpt, "regex", "", "Match the full path against the regular expression")
+	findCmd.Flags().StringVar(&findTypeOpt, "type", "", "Match only a file or a folder")
+	findCmd.Flags().StringVar(&minSizeOpt, "min-size", "", "Match files of at least the size, e.g. 10KiB, 5MiB, 1GiB (MB is read as MiB)")
+	findCmd.Flags().StringVar(&maxSizeOpt, "max-size", "", "Match files of at most the size, e.g. 10KiB, 5MiB, 1GiB (MB is read as MiB)")
+	findCmd.Flags().StringVar(&findMtimeOpt, "mtime", "", "Match items modified N days ago, +N more than or -N less than N days ago")
+	findCmd.Flags().StringVar(&findNewerOpt, "newer", "", "Match items modified after the timestamp, age or remote file, e.g. 2020-10-01, 36h, /inbox/last.csv")
+	findCmd.Flags().BoolVar(&findPrint0Opt, "print0", false, "Print the paths separated by a NUL character, e.g. for xargs -0")
+	findCmd.Flags().BoolVar(&findJSONOpt, "json", false, "Print every match as a JSON object per line")
+	findCmd.Flags().BoolVar(&findDeleteOpt, "delete", false, "Delete the matches after confirmation")
+	findCmd.Flags().StringVar(&findDownloadOpt, "download", "", "Download the matching files into the local folder, keeping their relative path")
+	findCmd.Flags().BoolVarP(&yesOpt, "yes", "y", false, "Delete without confirmation")
+	findCmd.Flags().IntVarP(&threadCnt, "threadCount", "t", 1, "Number of concurrent thread to download")
+	findCmd.Flags().Int64Var(&limitOpt, "limit", 100, "Number of items listed per page")
+}
+
+// findMatcher - the predicates of find, an item matches when it passes every predicate given
+type findMatcher struct {
+	name    string
+	iname   string
+	regex   *regexp.Regexp
+	kind    string
+	minSize int64
+	maxSize int64
+	after   time.Time
+	before  time.Time
+}
+
+func buildFindMatcher() (*findMatcher, error) {
+	m := &findMatcher{name: findNameOpt, iname: strings.ToLower(findINameOpt), kind: findTypeOpt}
+
+	for _, pattern := range []string{m.name, m.iname} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	var err error
+	if findRegexOpt != "" {
+		if m.regex, err = regexp.Compile(findRegexOpt); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", findRegexOpt, err)
+		}
+	}
+
+	switch m.kind {
+	case "", "file", "folder":
+	default:
+		return nil, fmt.Errorf("invalid type %q, use file or folder", m.kind)
+	}
+
+	if minSizeOpt != "" {
+		if m.minSize, err = util.ParseByteSize(minSizeOpt); err != nil {
+			return nil, err
+		}
+	}
+	if maxSizeOpt != "" {
+		if m.maxSize, err = util.ParseByteSize(maxSizeOpt); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if findMtimeOpt != "" {
+		if m.after, m.before, err = parseMtime(findMtimeOpt, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if findNewerOpt != "" {
+		newer, err := parseNewer(findNewerOpt, now)
+		if err != nil {
+			return nil, err
+		}
+		if newer.After(m.after) {
+			m.after = newer
+		}
+	}
+	return m, nil
+}
+
+// parseMtime - the window of modified time of N, +N or -N days ago, a zero time is not bounded
+func parseMtime(mtime string, now time.Time) (time.Time, time.Time, error) {
+	number := mtime
+	if strings.HasPrefix(mtime, "+") || strings.HasPrefix(mtime, "-") {
+		number = mtime[1:]
+	}
+	days, err := strconv.Atoi(number)
+	if err != nil || days < 0 || strings.HasPrefix(number, "+") {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid mtime %q, use N, +N or -N days", mtime)
+	}
+
+	day := 24 * time.Hour
+	switch mtime[0] {
+	case '+':
+		return time.Time{}, now.Add(-time.Duration(days+1) * day), nil
+	case '-':
+		return now.Add(-time.Duration(days) * day), time.Time{}, nil
+	}
+	return now.Add(-time.Duration(days+1) * day), now.Add(-time.Duration(days) * day), nil
+}
+
+// parseNewer - the modified time of the remote file when the value is a remote path, otherwise a timestamp or an age
+func parseNewer(newer string, now time.Time) (time.Time, error) {
+	if !strings.HasPrefix(newer, "/") {
+		return util.ParseTimeOrAge(newer, now)
+	}
+
+	item, err := api.StatItem(newer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if item.Kind == "folder" {
+		return time.Time{}, fmt.Errorf("%s is not a file", newer)
+	}
+	if item.ModifiedDate == nil {
+		return time.Time{}, fmt.Errorf("%s has no modified date", newer)
+	}
+	return *item.ModifiedDate, nil
+}
+
+func (m *findMatcher) match(item *model.Item) bool {
+	if m.kind != "" && item.Kind != m.kind {
+		return false
+	}
+	if m.name != "" {
+		if ok, _ := path.Match(m.name, item.Name); !ok {
+			return false
+		}
+	}
+	if m.iname != "" {
+		if ok, _ := path.Match(m.iname, strings.ToLower(item.Name)); !ok {
+			return false
+		}
+	}
+	if m.regex != nil && !m.regex.MatchString(item.Path) {
+		return false
+	}
+	if m.minSize > 0 && item.Size < m.minSize {
+		return false
+	}
+	if m.maxSize > 0 && item.Size > m.maxSize {
+		return false
+	}
+
+	if !m.after.IsZero() || !m.before.IsZero() {
+		if item.ModifiedDate == nil {
+			return false
+		}
+		if !m.after.IsZero() && !item.ModifiedDate.After(m.after) {
+			return false
+		}
+		if !m.before.IsZero() && !item.ModifiedDate.Before(m.before) {
+			return false
+		}
+	}
+	return true
+}
+
+func findCommand(cmd *cobra.Command, args []string) error {
+	root := "/"
+	if len(args) == 1 {
+		root = trimRemoteFolder(strings.TrimSpace(args[0]))
+	}
+
+	actions := 0
+	for _, action := range []bool{findPrint0Opt, findJSONOpt, findDeleteOpt, findDownloadOpt != ""} {
+		if action {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return fmt.Errorf("only one of --print0, --json, --delete and --download can be used")
+	}
+	if err := validateLimitOpt(); err != nil {
+		return err
+	}
+	if threadCnt < 1 {
+		return fmt.Errorf("threadCount must be at least 1")
+	}
+	matcher, err := buildFindMatcher()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	switch {
+	case findDeleteOpt:
+		return findAndDelete(root, matcher)
+	case findDownloadOpt != "":
+		return findAndDownload(root, matcher, findDownloadOpt)
+	}
+
+	vlog.SetOutput(os.Stderr)
+	stdout := bufio.NewWriter(os.Stdout)
+	var writer itemWriter = &pathItemWriter{w: stdout}
+	if findJSONOpt {
+		writer, _ = newItemWriter(outputNDJSON, stdout)
+	}
+
+	err = api.WalkItems(root, limitOpt, true, func(item *model.Item) error {
+		if !matcher.match(item) {
+			return nil
+		}
+		if findPrint0Opt {
+			_, err := fmt.Fprintf(stdout, "%s\x00", item.Path)
+			return err
+		}
+		return writer.write(item)
+	})
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// findAndDelete - delete the matches once confirmed, the matches are listed before anything is deleted
+func findAndDelete(root string, matcher *findMatcher) error {
+	// a cached listing would miss new matches and fail on the items already removed
+	config.RefreshCache = true
+	var matches []*model.Item
+	err := api.WalkItems(root, limitOpt, true, func(item *model.Item) error {
+		if matcher.match(item) {
+			matches = append(matches, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(matches) == 0 {
+		vlog.Infof("No items found")
+		return nil
+	}
+
+	// the content of a folder is deleted before the folder
+	sort.SliceStable(matches, func(i, j int) bool {
+		return strings.Count(strings.TrimSuffix(matches[i].Path, "/"), "/") > strings.Count(strings.TrimSuffix(matches[j].Path, "/"), "/")
+	})
+
+	if !yesOpt {
+		if !isInteractive() {
+			return fmt.Errorf("%d item(s) found, use --yes to delete them without confirmation", len(matches))
+		}
+		for _, item := range matches {
+			vlog.NoFormatLog(item.Path)
+		}
+		fmt.Printf("Delete %d item(s)? [y/N] ", len(matches))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			vlog.Infof("Nothing deleted")
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, item := range matches {
+		if err := api.DeleteItem(item.Path, false); err != nil {
+			vlog.Errorf("Failed to delete %s: %v", item.Path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d item(s) failed to delete", failed, len(matches))
+	}
+	return nil
+}
+
+// findAndDownload - download the matching files into the local folder as they are listed
+func findAndDownload(root string, matcher *findMatcher, localFolder string) error {
+	return downloadItems(root, true, func(item *model.Item) string {
+		if !matcher.match(item) {
+			return ""
+		}
+		relPath := strings.TrimPrefix(strings.TrimPrefix(item.Path, root), "/")
+		return filepath.Join(localFolder, relPath)
+	})
+}
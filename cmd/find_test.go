@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMtime(t *testing.T) {
+	now := time.Date(2020, 10, 20, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		mtime      string
+		wantAfter  time.Time
+		wantBefore time.Time
+		wantErr    bool
+	}{
+		{"0", now.Add(-day), now, false},
+		{"3", now.Add(-4 * day), now.Add(-3 * day), false},
+		{"+3", time.Time{}, now.Add(-4 * day), false},
+		{"-3", now.Add(-3 * day), time.Time{}, false},
+		{"+0", time.Time{}, now.Add(-day), false},
+		{"-0", now, time.Time{}, false},
+		{"", time.Time{}, time.Time{}, true},
+		{"+", time.Time{}, time.Time{}, true},
+		{"3d", time.Time{}, time.Time{}, true},
+		{"+-3", time.Time{}, time.Time{}, true},
+		{"--3", time.Time{}, time.Time{}, true},
+		{"++3", time.Time{}, time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		after, before, err := parseMtime(test.mtime, now)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseMtime(%q) expected error", test.mtime)
+			}
+			continue
+		}
+		if err != nil || !after.Equal(test.wantAfter) || !before.Equal(test.wantBefore) {
+			t.Errorf("parseMtime(%q) = %v, %v, %v, want %v, %v", test.mtime, after, before, err, test.wantAfter, test.wantBefore)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	skipReasonExists    = "EXISTS"
+	skipReasonUnchanged = "UNCHANGED"
+)
+
+// modTimeTolerance - file systems store the modified time with different precision
+const modTimeTolerance = time.Second
+
+var (
+	skipExistingOpt bool
+	updateOpt       bool
+	noClobberOpt    bool
+	backupOpt       bool
+)
+
+func validateIncrementalOpt() error {
+	if skipExistingOpt && updateOpt {
+		return fmt.Errorf("--skip-existing and --update cannot be used together")
+	}
+	if noClobberOpt && backupOpt {
+		return fmt.Errorf("--no-clobber and --backup cannot be used together")
+	}
+	return nil
+}
+
+// skipDownload - compare the local file with the remote item, returns the skipped result when the
+// local file does not need to be downloaded.  With --no-clobber any existing local file is kept, with
+// --skip-existing a local file of the same size is kept, with --update a local file of the same size
+// is kept when it has the modified time of the remote item or the same MD5.
+func skipDownload(item *model.DownloadItem) *model.TransferResult {
+	if noClobberOpt {
+		if _, err := os.Lstat(item.LocalPath); err == nil {
+			vlog.Debugf("Skipped %s, local file exists", item.LocalPath)
+			return newSkippedResult(item.LocalPath, item.RemotePath, skipReasonExists, nil)
+		}
+	}
+
+	if !skipExistingOpt && !updateOpt {
+		return nil
+	}
+
+	fi, err := os.Stat(item.LocalPath)
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() != item.Size {
+		return nil
+	}
+
+	if skipExistingOpt {
+		vlog.Debugf("Skipped %s, local file exists with the same size", item.LocalPath)
+		return newSkippedResult(item.LocalPath, item.RemotePath, skipReasonExists, nil)
+	}
+
+	if item.ModifiedDate != nil {
+		diff := fi.ModTime().Sub(*item.ModifiedDate)
+		if diff > -modTimeTolerance && diff < modTimeTolerance {
+			vlog.Debugf("Skipped %s, local file has the same size and modified time", item.LocalPath)
+			return newSkippedResult(item.LocalPath, item.RemotePath, skipReasonUnchanged, nil)
+		}
+	}
+
+	if item.MD5 == "" {
+		return nil
+	}
+
+	md5sum, err := util.FileMD5(item.LocalPath)
+	if err != nil || !strings.EqualFold(md5sum, item.MD5) {
+		return nil
+	}
+
+	// the next run compares the modified time without hashing the file
+	if item.ModifiedDate != nil {
+		api.SetModifiedTime(item.LocalPath, *item.ModifiedDate)
+	}
+	vlog.Debugf("Skipped %s, local file has the same MD5", item.LocalPath)
+	result := newSkippedResult(item.LocalPath, item.RemotePath, skipReasonUnchanged, nil)
+	result.MD5 = md5sum
+	result.Verification = model.VerifyPassed
+	return result
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/veeva/vvfst/model"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSkipDownload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vvfst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "a.csv")
+	if err := ioutil.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC)
+	otherTime := modTime.Add(time.Hour)
+	closeTime := modTime.Add(500 * time.Millisecond)
+	const md5sum = "5d41402abc4b2a76b9719d911017c592"
+
+	tests := []struct {
+		name         string
+		noClobber    bool
+		skipExisting bool
+		update       bool
+		localPath    string
+		size         int64
+		modifiedDate *time.Time
+		md5          string
+		want         string
+	}{
+		{"no option downloads", false, false, false, localPath, 5, &modTime, md5sum, ""},
+		{"no-clobber keeps existing", true, false, false, localPath, 9, nil, "", skipReasonExists},
+		{"no-clobber downloads missing", true, false, false, filepath.Join(dir, "b.csv"), 5, nil, "", ""},
+		{"skip-existing keeps same size", false, true, false, localPath, 5, &otherTime, "", skipReasonExists},
+		{"skip-existing downloads other size", false, true, false, localPath, 9, nil, "", ""},
+		{"skip-existing downloads missing", false, true, false, filepath.Join(dir, "b.csv"), 5, nil, "", ""},
+		{"skip-existing downloads over a folder", false, true, false, dir, 5, nil, "", ""},
+		{"update keeps same modified time", false, false, true, localPath, 5, &modTime, "", skipReasonUnchanged},
+		{"update keeps modified time within tolerance", false, false, true, localPath, 5, &closeTime, "", skipReasonUnchanged},
+		{"update keeps same MD5", false, false, true, localPath, 5, &otherTime, "5D41402ABC4B2A76B9719D911017C592", skipReasonUnchanged},
+		{"update downloads other MD5", false, false, true, localPath, 5, &otherTime, "00000000000000000000000000000000", ""},
+		{"update downloads other time without MD5", false, false, true, localPath, 5, &otherTime, "", ""},
+		{"update downloads other size", false, false, true, localPath, 9, &modTime, md5sum, ""},
+	}
+
+	defer func() {
+		noClobberOpt, skipExistingOpt, updateOpt = false, false, false
+	}()
+	for _, test := range tests {
+		if err := os.Chtimes(localPath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		noClobberOpt, skipExistingOpt, updateOpt = test.noClobber, test.skipExisting, test.update
+
+		item := &model.DownloadItem{RemotePath: "/a.csv", LocalPath: test.localPath, Size: test.size, ModifiedDate: test.modifiedDate, MD5: test.md5}
+		got := ""
+		if result := skipDownload(item); result != nil {
+			got = result.ErrorType
+		}
+		if got != test.want {
+			t.Errorf("%s: skipDownload() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats of ls, the structured formats write every field of the item with the full path
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputCSV    = "csv"
+	outputPaths  = "paths"
+)
+
+// Sort orders of ls
+const (
+	sortName = "name"
+	sortSize = "size"
+	sortTime = "time"
+)
+
+// listTimeFormat - modified time of the long listing
+const listTimeFormat = "2006-01-02 15:04:05 MST"
+
+var (
+	outputOpt  string
+	longOpt    bool
+	humanOpt   bool
+	utcOpt     bool
+	sortOpt    string
+	reverseOpt bool
+)
+
+var itemHeader = []string{"path", "name", "kind", "size", "modified_date", "file_content_md5"}
+
+// itemWriter - writes the listed items in an output format, close ends the output after the last item
+type itemWriter interface {
+	write(item *model.Item) error
+	close() error
+}
+
+func validateOutputOpt() error {
+	switch outputOpt {
+	case outputTable, outputJSON, outputNDJSON, outputCSV, outputPaths:
+		return nil
+	}
+	return fmt.Errorf("invalid output %q, use table, json, ndjson, csv or paths", outputOpt)
+}
+
+func validateSortOpt() error {
+	switch sortOpt {
+	case "", sortName, sortSize, sortTime:
+		return nil
+	}
+	return fmt.Errorf("invalid sort %q, use name, size or time", sortOpt)
+}
+
+// sortItems - sort by name, by size with the largest first or by modified time with the newest first,
+// reverse turns the order around.  Items of the same name, size or time are ordered by path.
+func sortItems(items []*model.Item, by string, reverse bool) {
+	compare := func(a, b *model.Item) int {
+		switch by {
+		case sortSize:
+			if a.Size != b.Size {
+				if a.Size > b.Size {
+					return -1
+				}
+				return 1
+			}
+		case sortTime:
+			switch {
+			case a.ModifiedDate == nil && b.ModifiedDate != nil:
+				return 1
+			case a.ModifiedDate != nil && b.ModifiedDate == nil:
+				return -1
+			case a.ModifiedDate != nil && !a.ModifiedDate.Equal(*b.ModifiedDate):
+				if a.ModifiedDate.After(*b.ModifiedDate) {
+					return -1
+				}
+				return 1
+			}
+		default:
+			if c := strings.Compare(a.Name, b.Name); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.Path, b.Path)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if reverse {
+			return compare(items[j], items[i]) < 0
+		}
+		return compare(items[i], items[j]) < 0
+	})
+}
+
+// newItemWriter - writer of a structured output format
+func newItemWriter(format string, w io.Writer) (itemWriter, error) {
+	switch format {
+	case outputJSON:
+		return &jsonItemWriter{w: w}, nil
+	case outputNDJSON:
+		return &ndjsonItemWriter{encoder: json.NewEncoder(w)}, nil
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(itemHeader); err != nil {
+			return nil, err
+		}
+		return &csvItemWriter{writer: writer}, nil
+	case outputPaths:
+		return &pathItemWriter{w: w}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format: %s", format)
+}
+
+// jsonItemWriter - a single JSON array written as the items are listed
+type jsonItemWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonItemWriter) write(item *model.Item) error {
+	content, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, content)
+	return err
+}
+
+func (j *jsonItemWriter) close() error {
+	if j.count == 0 {
+		_, err := fmt.Fprintln(j.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprint(j.w, "\n]\n")
+	return err
+}
+
+// ndjsonItemWriter - one JSON object per line
+type ndjsonItemWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonItemWriter) write(item *model.Item) error {
+	return n.encoder.Encode(item)
+}
+
+func (n *ndjsonItemWriter) close() error {
+	return nil
+}
+
+type csvItemWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvItemWriter) write(item *model.Item) error {
+	modifiedDate := ""
+	if item.ModifiedDate != nil {
+		modifiedDate = item.ModifiedDate.Format(time.RFC3339)
+	}
+	return c.writer.Write([]string{item.Path, item.Name, item.Kind, strconv.FormatInt(item.Size, 10), modifiedDate, item.MD5})
+}
+
+func (c *csvItemWriter) close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// pathItemWriter - one full path per line, e.g. for xargs
+type pathItemWriter struct {
+	w io.Writer
+}
+
+func (p *pathItemWriter) write(item *model.Item) error {
+	_, err := fmt.Fprintln(p.w, item.Path)
+	return err
+}
+
+func (p *pathItemWriter) close() error {
+	return nil
+}
+
+// tableItemWriter - the table of ls, the path is truncated to fit the column.  The long listing adds the
+// modified time and MD5 with the full path in the last column.  The table shows the SI size or the
+// number of bytes, the long listing the exact number of bytes followed by the SI size with human.
+type tableItemWriter struct {
+	w     io.Writer
+	long  bool
+	human bool
+	utc   bool
+}
+
+func newTableItemWriter(w io.Writer, itemPath string, long, human, utc bool) *tableItemWriter {
+	t := &tableItemWriter{w: w, long: long, human: human, utc: utc}
+
+	_, _ = fmt.Fprintf(w, "listing: %s\n", itemPath)
+	if !long {
+		_, _ = fmt.Fprintf(w, "%-6.6s  %-50.50s  %s\n", "kind", "path", "size")
+		_, _ = fmt.Fprintf(w, "====================================================================\n")
+		return t
+	}
+
+	size := fmt.Sprintf("%14s", "size")
+	if human {
+		size += fmt.Sprintf("  %9s", "")
+	}
+	header := fmt.Sprintf("%-6.6s  %s  %-23s  %-32s  %s", "kind", size, "modified", "md5", "path")
+	_, _ = fmt.Fprintln(w, header)
+	_, _ = fmt.Fprintln(w, strings.Repeat("=", len(header)))
+	return t
+}
+
+func (t *tableItemWriter) write(item *model.Item) error {
+	if !t.long {
+		s := util.FixedWidth(item.Path, 50, true)
+		size := strconv.FormatInt(item.Size, 10)
+		if t.human {
+			size = util.ByteCountSI(item.Size)
+		}
+		_, err := fmt.Fprintf(t.w, "%-6.6s  %-50.50s  %s\n", item.Kind, s, size)
+		return err
+	}
+
+	size := fmt.Sprintf("%14d", item.Size)
+	if t.human {
+		size += fmt.Sprintf("  %9s", util.ByteCountSI(item.Size))
+	}
+
+	modified := "-"
+	if item.ModifiedDate != nil {
+		if t.utc {
+			modified = item.ModifiedDate.UTC().Format(listTimeFormat)
+		} else {
+			modified = item.ModifiedDate.Local().Format(listTimeFormat)
+		}
+	}
+
+	md5sum := item.MD5
+	if md5sum == "" {
+		md5sum = "-"
+	}
+	_, err := fmt.Fprintf(t.w, "%-6.6s  %s  %-23s  %-32s  %s\n", item.Kind, size, modified, md5sum, item.Path)
+	return err
+}
+
+func (t *tableItemWriter) close() error {
+	return nil
+}
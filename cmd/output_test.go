@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/veeva/vvfst/model"
+	"testing"
+	"time"
+)
+
+func TestSortItems(t *testing.T) {
+	day1 := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+	newItems := func() []*model.Item {
+		return []*model.Item{
+			{Path: "/x/b.csv", Name: "b.csv", Size: 10, ModifiedDate: &day1},
+			{Path: "/a.xml", Name: "a.xml", Size: 30, ModifiedDate: &day1},
+			{Path: "/inbox", Name: "inbox"},
+			{Path: "/b.csv", Name: "b.csv", Size: 10, ModifiedDate: &day2},
+		}
+	}
+
+	tests := []struct {
+		by      string
+		reverse bool
+		want    []string
+	}{
+		{sortName, false, []string{"/a.xml", "/b.csv", "/x/b.csv", "/inbox"}},
+		{sortName, true, []string{"/inbox", "/x/b.csv", "/b.csv", "/a.xml"}},
+		{"", false, []string{"/a.xml", "/b.csv", "/x/b.csv", "/inbox"}},
+		{sortSize, false, []string{"/a.xml", "/b.csv", "/x/b.csv", "/inbox"}},
+		{sortSize, true, []string{"/inbox", "/x/b.csv", "/b.csv", "/a.xml"}},
+		{sortTime, false, []string{"/b.csv", "/a.xml", "/x/b.csv", "/inbox"}},
+		{sortTime, true, []string{"/inbox", "/x/b.csv", "/a.xml", "/b.csv"}},
+	}
+
+	for _, test := range tests {
+		items := newItems()
+		sortItems(items, test.by, test.reverse)
+
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Path)
+		}
+		for i := range test.want {
+			if got[i] != test.want[i] {
+				t.Errorf("sortItems(%q, %t) = %q, want %q", test.by, test.reverse, got, test.want)
+				break
+			}
+		}
+	}
+}
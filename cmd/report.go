@@ -0,0 +1,301 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/net"
+	"github.com/veeva/vvfst/vlog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	reportOpt   string
+	failFastOpt bool
+)
+
+var errTransferStopped = errors.New("transfer stopped")
+
+// transferReport - collects the transfer results from the worker pool, with fail fast the
+// first failure stops queueing of remaining items
+type transferReport struct {
+	mutex     sync.Mutex
+	results   []*model.TransferResult
+	failed    int
+	stopOnce  sync.Once
+	stop      chan struct{}
+	cancelled bool
+}
+
+func newTransferReport() *transferReport {
+	return &transferReport{stop: make(chan struct{})}
+}
+
+// reportRecord - one line of the report
+type reportRecord struct {
+	LocalPath       string  `json:"local_path"`
+	RemotePath      string  `json:"remote_path"`
+	Size            int64   `json:"size"`
+	MD5             string  `json:"md5"`
+	Method          string  `json:"method"`
+	Attempts        int     `json:"attempts"`
+	StartTime       string  `json:"start_time"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Status          string  `json:"status"`
+	Verification    string  `json:"verification"`
+	ErrorType       string  `json:"error_type"`
+	Error           string  `json:"error"`
+}
+
+var reportHeader = []string{"local_path", "remote_path", "size", "md5", "method", "attempts", "start_time",
+	"duration_seconds", "status", "verification", "error_type", "error"}
+
+// validateReportOpt - the report format is chosen by the file extension, either .json or .csv
+func validateReportOpt() error {
+	if reportOpt == "" {
+		return nil
+	}
+
+	switch strings.ToLower(filepath.Ext(reportOpt)) {
+	case ".json", ".csv":
+	default:
+		return fmt.Errorf("report must be a .json or .csv file: %s", reportOpt)
+	}
+
+	// the report is written once the transfer is done, make sure it can be created before it starts
+	_, statErr := os.Stat(reportOpt)
+	f, err := os.OpenFile(reportOpt, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot create report: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cannot create report: %v", err)
+	}
+	if os.IsNotExist(statErr) {
+		_ = os.Remove(reportOpt)
+	}
+	return nil
+}
+
+func (r *transferReport) add(result *model.TransferResult) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.results = append(r.results, result)
+	if result.Status == model.TransferFailed {
+		r.failed++
+		if failFastOpt {
+			r.stopOnce.Do(func() {
+				vlog.Warnf("Stopping after the first failure, remaining items are not transferred")
+				close(r.stop)
+			})
+		}
+	}
+}
+
+// cancel - stop queueing the remaining items, the command was cancelled
+func (r *transferReport) cancel() {
+	r.mutex.Lock()
+	r.cancelled = true
+	r.mutex.Unlock()
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// interrupted - the error of a cancelled command, it exits with the status of Ctrl-C once the report is
+// written, nil when the command was not cancelled
+func (r *transferReport) interrupted(action string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.cancelled {
+		return nil
+	}
+	return &exitError{code: exitCodeInterrupted, err: fmt.Errorf("%s cancelled", action)}
+}
+
+// stopped - Return true when no more items should be transferred
+func (r *transferReport) stopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// summarize - log the totals and every failed item, returns an error when any item failed
+func (r *transferReport) summarize(action string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	succeeded, skipped := 0, 0
+	verified, unverified, mismatched, retried := 0, 0, 0, 0
+	for _, result := range r.results {
+		switch result.Status {
+		case model.TransferSucceeded:
+			succeeded++
+		case model.TransferSkipped:
+			skipped++
+		}
+
+		switch result.Verification {
+		case model.VerifyPassed:
+			verified++
+		case model.VerifySkipped:
+			unverified++
+		case model.VerifyMismatch:
+			mismatched++
+		}
+		if result.Verification != "" && result.Attempts > 1 {
+			retried++
+		}
+	}
+
+	if len(r.results) > 1 || r.failed > 0 {
+		vlog.Infof("%s summary: %d succeeded, %d failed, %d skipped", action, succeeded, r.failed, skipped)
+	}
+
+	if verified+unverified+mismatched > 0 && (len(r.results) > 1 || mismatched > 0 || retried > 0) {
+		vlog.Infof("%s verification: %d verified, %d unverified, %d mismatched, %d downloaded again after a mismatch",
+			action, verified, unverified, mismatched, retried)
+	}
+
+	if r.failed == 0 {
+		return nil
+	}
+
+	for _, result := range r.results {
+		if result.Status == model.TransferFailed {
+			vlog.Errorf("Failed: %s [%s] %v", describeTransfer(result), result.ErrorType, result.Err)
+		}
+	}
+	return fmt.Errorf("%s failed for %d of %d item(s)", strings.ToLower(action), r.failed, len(r.results))
+}
+
+func newSkippedResult(localPath, remotePath, reason string, err error) *model.TransferResult {
+	return &model.TransferResult{
+		LocalPath:  localPath,
+		RemotePath: remotePath,
+		StartTime:  time.Now(),
+		Status:     model.TransferSkipped,
+		ErrorType:  reason,
+		Err:        err,
+	}
+}
+
+func newFailedResult(localPath, remotePath string, err error) *model.TransferResult {
+	return &model.TransferResult{
+		LocalPath:  localPath,
+		RemotePath: remotePath,
+		StartTime:  time.Now(),
+		Status:     model.TransferFailed,
+		ErrorType:  net.ErrorType(err),
+		Err:        err,
+	}
+}
+
+func describeTransfer(result *model.TransferResult) string {
+	if result.LocalPath == "" {
+		return result.RemotePath
+	}
+	return fmt.Sprintf("%s -> %s", result.LocalPath, result.RemotePath)
+}
+
+// writeReport - write the report when requested with --report, returns the error of the command or, when
+// the command succeeded, the error writing the report so the command does not exit 0 without its report
+func (r *transferReport) writeReport(cmdErr error) error {
+	if reportOpt == "" {
+		return cmdErr
+	}
+
+	if err := r.write(reportOpt); err != nil {
+		err = fmt.Errorf("failed to write report %s: %v", reportOpt, err)
+		if cmdErr == nil {
+			return err
+		}
+		vlog.Errorf("%v", err)
+		return cmdErr
+	}
+	vlog.Infof("Transfer report written to %s", reportOpt)
+	return cmdErr
+}
+
+func (r *transferReport) write(filename string) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	records := make([]*reportRecord, 0, len(r.results))
+	for _, result := range r.results {
+		records = append(records, newReportRecord(result))
+	}
+
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+
+	w := csv.NewWriter(f)
+	_ = w.Write(reportHeader)
+	for _, rec := range records {
+		_ = w.Write([]string{rec.LocalPath, rec.RemotePath, strconv.FormatInt(rec.Size, 10), rec.MD5, rec.Method,
+			strconv.Itoa(rec.Attempts), rec.StartTime, strconv.FormatFloat(rec.DurationSeconds, 'f', 3, 64),
+			rec.Status, rec.Verification, rec.ErrorType, rec.Error})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func newReportRecord(result *model.TransferResult) *reportRecord {
+	rec := &reportRecord{
+		LocalPath:       result.LocalPath,
+		RemotePath:      result.RemotePath,
+		Size:            result.Size,
+		MD5:             result.MD5,
+		Method:          string(result.Method),
+		Attempts:        result.Attempts,
+		StartTime:       result.StartTime.UTC().Format(time.RFC3339),
+		DurationSeconds: result.Duration.Seconds(),
+		Status:          string(result.Status),
+		Verification:    string(result.Verification),
+		ErrorType:       result.ErrorType,
+	}
+	if result.Err != nil {
+		rec.Error = result.Err.Error()
+	}
+	return rec
+}
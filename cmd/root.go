@@ -16,6 +16,7 @@ and limitations under the License.
 package cmd
 
 import (
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/veeva/vvfst/config"
 	"github.com/veeva/vvfst/vlog"
@@ -54,12 +55,41 @@ either express or implied. See the License for the specific language governing p
 and limitations under the License.
 =============================================================================================
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		for name, key := range configFlags {
+			if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+				config.SetFlagValue(key, flag.Value.String())
+			}
+		}
+		return config.ValidateCacheTTL()
+	},
+}
+
+// configFlags - flags which override a configuration key for the current run without saving it
+var configFlags = map[string]string{
+	"part-size":           config.ConfigKeyPartSize,
+	"multipart-threshold": config.ConfigKeyMultipartThreshold,
+	"segment-size":        config.ConfigKeySegmentSize,
+	"segment-threshold":   config.ConfigKeySegmentThreshold,
+	"cache-ttl":           config.ConfigKeyCacheTTL,
 }
 
 func init() {
 	config.InitConfig()
 
 	rootCmd.PersistentFlags().BoolVarP(&config.EnableDebug, "debug", "x", false, "Enable debug")
+	rootCmd.PersistentFlags().String("cache-ttl", "", "Cache remote listings on disk for the duration, e.g. 10m, empty or 0 disables the cache")
+	rootCmd.PersistentFlags().BoolVar(&config.RefreshCache, "refresh", false, "List the remote folders again instead of using the cached listings")
+}
+
+// exitError - error of a command which exits with a specific status instead of 1
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -67,6 +97,10 @@ func init() {
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		vlog.Errorf("%v", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,56 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/veeva/vvfst/vlog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitCodeInterrupted - exit status of a command cancelled with Ctrl-C
+const exitCodeInterrupted = 130
+
+// handleInterrupt - cancel the command on Ctrl-C, the command stops queueing items and lets the workers
+// drain so its report is written before it exits with exitCodeInterrupted.  A second signal exits at once.
+// The returned function stops watching for the signal.
+func handleInterrupt(cancel func()) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			vlog.Warnf("Cancelled by %v, stopping the transfers in progress", sig)
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			os.Exit(exitCodeInterrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
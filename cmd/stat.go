@@ -0,0 +1,111 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"os"
+	"strings"
+)
+
+// exitCodeNotFound - exit status of stat when the remote path does not exist
+const exitCodeNotFound = 2
+
+const (
+	statOutputText = "text"
+	statOutputJSON = "json"
+)
+
+var statOutputOpt string
+
+var statCmd = &cobra.Command{
+	Use:   "stat <remote-path>",
+	Short: "Show the metadata of a remote file or folder",
+	Long: `Show the path, kind, size, modified time and MD5 of a remote file or folder, a folder has only a path
+and kind.  Logs are written to stderr.
+
+The exit status is 0 when the item exists, 2 when it does not exist and 1 for any other error, e.g.
+  if vvfst stat /inbox/done.csv > /dev/null 2>&1; then ...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithAutoLogin(cmd, args, statCommand)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statCmd)
+	statCmd.Flags().StringVarP(&statOutputOpt, "output", "o", statOutputText, "Output format: text or json")
+	statCmd.Flags().BoolVar(&utcOpt, "utc", false, "Show the modified time in UTC instead of the local time")
+}
+
+func statCommand(cmd *cobra.Command, args []string) error {
+	vlog.SetOutput(os.Stderr)
+	if len(args) != 1 {
+		return fmt.Errorf("missing required arg <remote-path>")
+	}
+	if statOutputOpt != statOutputText && statOutputOpt != statOutputJSON {
+		return fmt.Errorf("invalid output %q, use text or json", statOutputOpt)
+	}
+	cmd.SilenceUsage = true
+
+	item, err := api.StatItem(trimRemoteFolder(strings.TrimSpace(args[0])))
+	if errors.Cause(err) == api.ErrItemNotFound {
+		return &exitError{code: exitCodeNotFound, err: err}
+	}
+	if err != nil {
+		return err
+	}
+
+	stdout := bufio.NewWriter(os.Stdout)
+	if statOutputOpt == statOutputJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(item)
+	} else {
+		err = writeStat(stdout, item)
+	}
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// writeStat - one field of the item per line, fields a folder does not have are left out
+func writeStat(w io.Writer, item *model.Item) error {
+	fmt.Fprintf(w, "Path:      %s\n", item.Path)
+	fmt.Fprintf(w, "Kind:      %s\n", item.Kind)
+	if item.Kind == "folder" {
+		return nil
+	}
+
+	fmt.Fprintf(w, "Size:      %d\n", item.Size)
+	if item.ModifiedDate != nil {
+		modified := item.ModifiedDate.Local()
+		if utcOpt {
+			modified = item.ModifiedDate.UTC()
+		}
+		fmt.Fprintf(w, "Modified:  %s\n", modified.Format(listTimeFormat))
+	}
+	_, err := fmt.Fprintf(w, "MD5:       %s\n", item.MD5)
+	return err
+}
@@ -0,0 +1,268 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/veeva/vvfst/api"
+	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
+	"github.com/veeva/vvfst/vlog"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Output formats of tree
+const (
+	treeOutputText = "text"
+	treeOutputJSON = "json"
+)
+
+var (
+	depthOpt      int
+	dirsOnlyOpt   bool
+	treeOutputOpt string
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree <remote-folder>",
+	Short: "Show a folder and its sub directories as a tree",
+	Long: `Show a folder and its sub directories as a tree, every folder shows the number of files and the total size
+of the files under it, including its sub directories`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWithAutoLogin(cmd, args, treeCommand)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+	treeCmd.Flags().IntVar(&depthOpt, "depth", 0, "Show only the number of folder levels, 0 shows every level")
+	treeCmd.Flags().BoolVar(&dirsOnlyOpt, "dirs-only", false, "Show only folders")
+	treeCmd.Flags().StringVarP(&treeOutputOpt, "output", "o", treeOutputText, "Output format: text or json")
+	treeCmd.Flags().Int64Var(&limitOpt, "limit", 100, "Number of items listed per page")
+	addFilterFlags(treeCmd)
+}
+
+// treeNode - a file or a folder of the tree, a folder counts the files and their size under it
+type treeNode struct {
+	Path         string      `json:"path"`
+	Name         string      `json:"name"`
+	Kind         string      `json:"kind"`
+	Size         int64       `json:"size"`
+	ModifiedDate *time.Time  `json:"modified_date,omitempty"`
+	MD5          string      `json:"file_content_md5,omitempty"`
+	Files        int         `json:"files,omitempty"`
+	Folders      int         `json:"folders,omitempty"`
+	Children     []*treeNode `json:"children,omitempty"`
+	children     map[string]*treeNode
+}
+
+func newFolderNode(folderPath string) *treeNode {
+	name := path.Base(folderPath)
+	return &treeNode{Path: folderPath, Name: name, Kind: "folder", children: map[string]*treeNode{}}
+}
+
+// folder - the sub folder of the relative path, created with its parents when not listed yet
+func (n *treeNode) folder(relPath string) *treeNode {
+	node := n
+	for _, name := range strings.Split(relPath, "/") {
+		child, ok := node.children[name]
+		if !ok {
+			child = newFolderNode(path.Join(node.Path, name))
+			node.children[name] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// add - add the file at the relative path, counting it in every folder above it
+func (n *treeNode) add(relPath string, item *model.Item) {
+	parent := n
+	if dir := path.Dir(relPath); dir != "." {
+		parent = n.folder(dir)
+	}
+	parent.children[path.Base(relPath)] = &treeNode{Path: item.Path, Name: item.Name, Kind: item.Kind,
+		Size: item.Size, ModifiedDate: item.ModifiedDate, MD5: item.MD5}
+}
+
+// summarize - count the files, folders and size under every folder and sort the children by name
+func (n *treeNode) summarize() {
+	if n.Kind != "folder" {
+		return
+	}
+
+	n.Children = n.Children[:0]
+	for _, child := range n.children {
+		child.summarize()
+		n.Children = append(n.Children, child)
+		if child.Kind == "folder" {
+			n.Folders += child.Folders + 1
+			n.Files += child.Files
+		} else {
+			n.Files++
+		}
+		n.Size += child.Size
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+}
+
+// prune - drop the children below the depth and the files with --dirs-only, the counts are kept.  A
+// negative depth keeps every level.
+func (n *treeNode) prune(depth int, dirsOnly bool) {
+	children := n.Children[:0]
+	for _, child := range n.Children {
+		if dirsOnly && child.Kind != "folder" {
+			continue
+		}
+		if depth == 1 {
+			child.Children = nil
+		} else {
+			child.prune(depth-1, dirsOnly)
+		}
+		children = append(children, child)
+	}
+	n.Children = children
+}
+
+// buildTree - the tree of the folder from the recursive listing with the files and folders accepted by the
+// filter, the counts and sizes of every folder are summarized
+func buildTree(root string, filter *itemFilter) (*treeNode, error) {
+	tree := newFolderNode(root)
+	tree.Name = root
+	err := api.WalkItems(root, limitOpt, true, func(item *model.Item) error {
+		relPath := strings.Trim(strings.TrimPrefix(item.Path, root), "/")
+		if relPath == "" {
+			return nil
+		}
+
+		if item.Kind == "folder" {
+			if !filter.skipDir(relPath) && !filter.skipParents(relPath) {
+				tree.folder(relPath)
+			}
+			return nil
+		}
+		if filter.acceptItem(relPath, item) {
+			tree.add(relPath, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree.summarize()
+	return tree, nil
+}
+
+// trimRemoteFolder - the folder path without a trailing separator, / for the root
+func trimRemoteFolder(folder string) string {
+	if folder == "" {
+		return "/"
+	}
+	if folder != "/" && util.EndWithFileSeparator(folder) {
+		return util.TrimLastChar(folder)
+	}
+	return folder
+}
+
+func treeCommand(cmd *cobra.Command, args []string) error {
+	root := "/"
+	if len(args) == 1 {
+		root = trimRemoteFolder(strings.TrimSpace(args[0]))
+	}
+
+	if treeOutputOpt != treeOutputText && treeOutputOpt != treeOutputJSON {
+		return fmt.Errorf("invalid output %q, use text or json", treeOutputOpt)
+	}
+	if depthOpt < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
+	if err := validateLimitOpt(); err != nil {
+		return err
+	}
+	filter, err := buildItemFilter("")
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	if treeOutputOpt == treeOutputJSON {
+		vlog.SetOutput(os.Stderr)
+	}
+
+	tree, err := buildTree(root, filter)
+	if err != nil {
+		return err
+	}
+
+	depth := depthOpt
+	if depth == 0 {
+		depth = -1
+	}
+	tree.prune(depth, dirsOnlyOpt)
+
+	stdout := bufio.NewWriter(os.Stdout)
+	if treeOutputOpt == treeOutputJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(tree)
+	} else {
+		writeTree(stdout, tree)
+	}
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// writeTree - draw the tree with one line per file and folder
+func writeTree(w io.Writer, tree *treeNode) {
+	_, _ = fmt.Fprintf(w, "%s  %s\n", tree.Name, describeTreeNode(tree))
+	writeTreeChildren(w, tree, "")
+	_, _ = fmt.Fprintf(w, "\n%d folders, %d files, %s\n", tree.Folders, tree.Files, util.ByteCountSI(tree.Size))
+}
+
+func writeTreeChildren(w io.Writer, node *treeNode, indent string) {
+	for i, child := range node.Children {
+		branch, next := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		name := child.Name
+		if child.Kind == "folder" {
+			name += "/"
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s  %s\n", indent, branch, name, describeTreeNode(child))
+		writeTreeChildren(w, child, indent+next)
+	}
+}
+
+func describeTreeNode(node *treeNode) string {
+	if node.Kind != "folder" {
+		return util.ByteCountSI(node.Size)
+	}
+	return fmt.Sprintf("(%d files, %s)", node.Files, util.ByteCountSI(node.Size))
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"github.com/veeva/vvfst/model"
+	"testing"
+)
+
+func newTestTree() *treeNode {
+	tree := newFolderNode("/")
+	tree.folder("inbox/2020")
+	tree.folder("inbox/empty")
+	tree.add("inbox/2020/a.xml", &model.Item{Path: "/inbox/2020/a.xml", Name: "a.xml", Kind: "file", Size: 3})
+	tree.add("inbox/c.txt", &model.Item{Path: "/inbox/c.txt", Name: "c.txt", Kind: "file", Size: 5})
+	tree.add("b.csv", &model.Item{Path: "/b.csv", Name: "b.csv", Kind: "file", Size: 4})
+	tree.summarize()
+	return tree
+}
+
+// treePaths - the paths of the tree in the order they are written
+func treePaths(n *treeNode) []string {
+	paths := []string{n.Path}
+	for _, child := range n.Children {
+		paths = append(paths, treePaths(child)...)
+	}
+	return paths
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTreeRootPaths(t *testing.T) {
+	tree := newFolderNode("/")
+	tree.folder("inbox/2020")
+	tree.add("inbox/2020/a.xml", &model.Item{Path: "/inbox/2020/a.xml", Name: "a.xml", Kind: "file", Size: 3})
+	tree.add("b.csv", &model.Item{Path: "/b.csv", Name: "b.csv", Kind: "file", Size: 4})
+	tree.summarize()
+
+	want := []string{"/", "/b.csv", "/inbox", "/inbox/2020", "/inbox/2020/a.xml"}
+	if got := treePaths(tree); !equalPaths(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
+
+func TestTreeSummarize(t *testing.T) {
+	tree := newTestTree()
+	folders := map[string]*treeNode{}
+	var index func(n *treeNode)
+	index = func(n *treeNode) {
+		folders[n.Path] = n
+		for _, child := range n.Children {
+			index(child)
+		}
+	}
+	index(tree)
+
+	tests := []struct {
+		path        string
+		wantFiles   int
+		wantFolders int
+		wantSize    int64
+	}{
+		{"/", 3, 3, 12},
+		{"/inbox", 2, 2, 8},
+		{"/inbox/2020", 1, 0, 3},
+		{"/inbox/empty", 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		n := folders[test.path]
+		if n == nil {
+			t.Errorf("summarize() missing folder %q", test.path)
+			continue
+		}
+		if n.Files != test.wantFiles || n.Folders != test.wantFolders || n.Size != test.wantSize {
+			t.Errorf("summarize() %q = %d files, %d folders, %d bytes, want %d, %d, %d",
+				test.path, n.Files, n.Folders, n.Size, test.wantFiles, test.wantFolders, test.wantSize)
+		}
+	}
+}
+
+func TestTreePrune(t *testing.T) {
+	tests := []struct {
+		depth    int
+		dirsOnly bool
+		want     []string
+	}{
+		{-1, false, []string{"/", "/b.csv", "/inbox", "/inbox/2020", "/inbox/2020/a.xml", "/inbox/c.txt", "/inbox/empty"}},
+		{1, false, []string{"/", "/b.csv", "/inbox"}},
+		{2, false, []string{"/", "/b.csv", "/inbox", "/inbox/2020", "/inbox/c.txt", "/inbox/empty"}},
+		{-1, true, []string{"/", "/inbox", "/inbox/2020", "/inbox/empty"}},
+		{1, true, []string{"/", "/inbox"}},
+	}
+
+	for _, test := range tests {
+		tree := newTestTree()
+		tree.prune(test.depth, test.dirsOnly)
+		if got := treePaths(tree); !equalPaths(got, test.want) {
+			t.Errorf("prune(%d, %t) = %q, want %q", test.depth, test.dirsOnly, got, test.want)
+		}
+		if tree.Files != 3 || tree.Folders != 3 || tree.Size != 12 {
+			t.Errorf("prune(%d, %t) counts = %d files, %d folders, %d bytes, want 3, 3, 12", test.depth, test.dirsOnly, tree.Files, tree.Folders, tree.Size)
+		}
+	}
+}
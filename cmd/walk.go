@@ -0,0 +1,210 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	skipReasonSymlink       = "SYMLINK_SKIPPED"
+	skipReasonBrokenSymlink = "BROKEN_SYMLINK"
+	skipReasonSymlinkLoop   = "SYMLINK_LOOP"
+	skipReasonSpecialFile   = "SPECIAL_FILE"
+)
+
+var (
+	followSymlinksOpt bool
+	skipSymlinksOpt   bool
+)
+
+// localWalker - walks a local folder in lexical order like filepath.Walk, symbolic links are either
+// followed with loop detection or skipped, and every item that cannot be uploaded is reported
+type localWalker struct {
+	followSymlinks bool
+
+	// visitDir - called for every folder, return filepath.SkipDir to skip it
+	visitDir func(path, relPath string, info os.FileInfo) error
+	// visitFile - called for every regular file
+	visitFile func(path, relPath string, info os.FileInfo) error
+	// skip - called for symbolic links, special files and loops which are not uploaded
+	skip func(path, relPath, reason string, err error)
+	// excluded - optional, return true if the filter rules out the item so it is not reported as skipped
+	excluded func(relPath string, isDir bool) bool
+	// fail - called when a file or folder cannot be read
+	fail func(path, relPath string, err error)
+}
+
+// walk - walk the root folder, a symbolic link given as root is always followed
+func (w *localWalker) walk(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	return w.walkItem(root, ".", info, nil)
+}
+
+func (w *localWalker) walkItem(path, relPath string, info os.FileInfo, ancestors []os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Stat(path)
+		if err != nil {
+			w.skipItem(path, relPath, false, skipReasonBrokenSymlink, fmt.Errorf("broken symbolic link: %v", err))
+			return nil
+		}
+
+		if !w.followSymlinks {
+			w.skipItem(path, relPath, target.IsDir(), skipReasonSymlink, fmt.Errorf("symbolic link is skipped, use --follow-symlinks to upload the target"))
+			return nil
+		}
+		info = target
+	}
+
+	switch {
+	case info.IsDir():
+		for _, ancestor := range ancestors {
+			if os.SameFile(ancestor, info) {
+				w.skipItem(path, relPath, true, skipReasonSymlinkLoop, fmt.Errorf("symbolic link loop, folder is already being walked"))
+				return nil
+			}
+		}
+
+		err := w.visitDir(path, relPath, info)
+		if err == filepath.SkipDir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		names, err := readDirNames(path)
+		if err != nil {
+			w.fail(path, relPath, err)
+			return nil
+		}
+
+		ancestors = append(ancestors, info)
+		for _, name := range names {
+			childPath := filepath.Join(path, name)
+			childRelPath := name
+			if relPath != "." {
+				childRelPath = relPath + "/" + name
+			}
+
+			childInfo, err := os.Lstat(childPath)
+			if err != nil {
+				w.fail(childPath, childRelPath, err)
+				continue
+			}
+
+			if err := w.walkItem(childPath, childRelPath, childInfo, ancestors); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case info.Mode().IsRegular():
+		return w.visitFile(path, relPath, info)
+
+	default:
+		w.skipItem(path, relPath, false, skipReasonSpecialFile, fmt.Errorf("%s is skipped", describeFileType(info.Mode())))
+		return nil
+	}
+}
+
+// skipItem - report the item as skipped unless the filter excludes it anyway
+func (w *localWalker) skipItem(path, relPath string, isDir bool, reason string, err error) {
+	if relPath != "." && w.excluded != nil && w.excluded(relPath, isDir) {
+		return
+	}
+	w.skip(path, relPath, reason, err)
+}
+
+// folderCreator - creates the remote folders of an upload once each, every folder as it is visited.  When
+// files are selected by include patterns, size or time a folder is only created once it holds an accepted
+// file, so folders without any selected file are not created.
+type folderCreator struct {
+	lazy bool
+	// create - create the remote folder of the relative path, false when it failed or is excluded
+	create  func(relPath string) bool
+	created map[string]bool
+}
+
+func newFolderCreator(lazy bool, create func(relPath string) bool) *folderCreator {
+	return &folderCreator{lazy: lazy, create: create, created: map[string]bool{}}
+}
+
+// visitDir - create the visited folder unless it waits for an accepted file, false when it failed
+func (c *folderCreator) visitDir(relPath string) bool {
+	if c.lazy {
+		return true
+	}
+	return c.folder(relPath)
+}
+
+// folder - create the folder and the folders above it, false when one of them failed or is excluded
+func (c *folderCreator) folder(relPath string) bool {
+	if created, ok := c.created[relPath]; ok {
+		return created
+	}
+
+	created := relPath == "." || c.folder(parentRelPath(relPath))
+	if created {
+		created = c.create(relPath)
+	}
+	c.created[relPath] = created
+	return created
+}
+
+// parentRelPath - relative path of the folder holding the item, "." for an item of the walked root
+func parentRelPath(relPath string) string {
+	if i := strings.LastIndex(relPath, "/"); i >= 0 {
+		return relPath[:i]
+	}
+	return "."
+}
+
+func readDirNames(dirname string) ([]string, error) {
+	f, err := os.Open(dirname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func describeFileType(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&os.ModeCharDevice != 0:
+		return "character device"
+	case mode&os.ModeDevice != 0:
+		return "device"
+	}
+	return "special file"
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWalkCreatesFolders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vvfst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, folder := range []string{"empty", "sub/deep", "txt"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(folder)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"a.txt", "sub/b.xml", "txt/c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(file)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		lazy      bool
+		want      []string
+		wantFiles []string
+	}{
+		{false, []string{".", "empty", "sub", "sub/deep", "txt"}, []string{"sub/b.xml"}},
+		{true, []string{".", "sub"}, []string{"sub/b.xml"}},
+	}
+
+	for _, test := range tests {
+		var created, files []string
+		folders := newFolderCreator(test.lazy, func(relPath string) bool {
+			created = append(created, relPath)
+			return true
+		})
+
+		walker := &localWalker{
+			visitDir: func(path, relPath string, info os.FileInfo) error {
+				if !folders.visitDir(relPath) {
+					return filepath.SkipDir
+				}
+				return nil
+			},
+			visitFile: func(path, relPath string, info os.FileInfo) error {
+				// only the xml files are accepted
+				if filepath.Ext(relPath) == ".xml" && folders.folder(parentRelPath(relPath)) {
+					files = append(files, relPath)
+				}
+				return nil
+			},
+			skip: func(path, relPath, reason string, err error) {},
+			fail: func(path, relPath string, err error) {
+				t.Errorf("walk failed to read %s: %v", path, err)
+			},
+		}
+		if err := walker.walk(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(created, ",") != strings.Join(test.want, ",") || strings.Join(files, ",") != strings.Join(test.wantFiles, ",") {
+			t.Errorf("walk(lazy %t) created %q, files %q, want %q, %q", test.lazy, created, files, test.want, test.wantFiles)
+		}
+	}
+}
+
+func TestFolderCreatorFailedParent(t *testing.T) {
+	var created []string
+	folders := newFolderCreator(true, func(relPath string) bool {
+		created = append(created, relPath)
+		return relPath != "sub"
+	})
+
+	tests := []struct {
+		relPath string
+		want    bool
+	}{
+		{"sub/deep", false},
+		{"sub/deep/x", false},
+		{"other", true},
+		{".", true},
+	}
+
+	for _, test := range tests {
+		if got := folders.folder(test.relPath); got != test.want {
+			t.Errorf("folder(%q) = %t, want %t", test.relPath, got, test.want)
+		}
+	}
+
+	// every folder is tried once, the folders under a failed one are not tried
+	if want := ".,sub,other"; strings.Join(created, ",") != want {
+		t.Errorf("created %q, want %s", created, want)
+	}
+}
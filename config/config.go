@@ -20,68 +20,287 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 	"github.com/veeva/vvfst/model"
+	"github.com/veeva/vvfst/util"
 	"github.com/veeva/vvfst/vlog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync"
+	"time"
 )
 
 const (
+	Size1MB           = 1024 * 1024
 	Size5MB           = 5 * 1024 * 1024
 	Size50MB          = 50 * 1024 * 1024
 	JobTimeoutSeconds = 60
+	DownloadAttempts  = 3
+)
+
+// Limits of the multipart upload API, every part except the last one is at least MinPartSize
+const (
+	MinPartSize  = Size5MB
+	MaxPartSize  = Size50MB
+	MaxPartCount = 10000
+	PartAttempts = 3
+	PartSizeAuto = "auto"
 )
 
 var EnableDebug bool
 
+// RefreshCache - list the remote folders again instead of using the cached listings
+var RefreshCache bool
+
 var cfgFile string
 var initialized bool
 
 const (
-	ConfigKeyDomainName   = "domain_name"
-	ConfigKeyAPIVersion   = "api_version"
-	ConfigKeyUsername     = "username"
-	ConfigKeyPassword     = "password"
-	ConfigAuthResult      = "auth_result"
-	ConfigUploadSessionID = "upload_session_id"
-	ConfigActiveJobIDs    = "active_jobs"
+	ConfigKeyDomainName  = "domain_name"
+	ConfigKeyAPIVersion  = "api_version"
+	ConfigKeyUsername    = "username"
+	ConfigKeyPassword    = "password"
+	ConfigAuthResult     = "auth_result"
+	ConfigUploadSessions = "upload_sessions"
+	ConfigActiveJobIDs   = "active_jobs"
+
+	ConfigKeyPartSize           = "part_size"
+	ConfigKeyMultipartThreshold = "multipart_threshold"
+	ConfigKeySegmentSize        = "segment_size"
+	ConfigKeySegmentThreshold   = "segment_threshold"
+	ConfigKeyCacheTTL           = "cache_ttl"
 )
 
 // DomainName - return domain name from configuration
 func DomainName() string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
 	return viper.GetString(ConfigKeyDomainName)
 }
 
 // APIVersion - return api version from configuration
 func APIVersion() string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
 	return viper.GetString(ConfigKeyAPIVersion)
 }
 
 // Username - return username from configuration
 func Username() string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
 	return viper.GetString(ConfigKeyUsername)
 }
 
 // Password - return password from configuration
 func Password() string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
 	return viper.GetString(ConfigKeyPassword)
 }
 
 // SetPassword - store password in the configuration
 func SetPassword(password string) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
 	viper.Set(ConfigKeyPassword, password)
 }
 
-// UploadSessionID - return upload session id from configuration
-func UploadSessionID() string {
-	return viper.GetString(ConfigUploadSessionID)
+// flagValues - configuration values given as flags, they apply to the current run only and are never
+// written to the configuration file
+var flagValues = map[string]string{}
+
+// SetFlagValue - use the flag value instead of the configured value of the key for the current run
+func SetFlagValue(key, value string) {
+	flagValues[key] = value
+}
+
+// configString - return the flag value of the key when given, otherwise the configured value
+func configString(key string) string {
+	if value, ok := flagValues[key]; ok {
+		return value
+	}
+
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return viper.GetString(key)
+}
+
+// PartSize - return the multipart part size from configuration, either a size, auto or empty to
+// choose the size by the file size
+func PartSize() string {
+	return configString(ConfigKeyPartSize)
+}
+
+// MultipartThreshold - return the file size above which multipart upload is used
+func MultipartThreshold() int64 {
+	threshold, err := util.ParseByteSize(configString(ConfigKeyMultipartThreshold))
+	if err != nil || threshold == 0 {
+		return Size50MB
+	}
+	return threshold
+}
+
+// ValidatePartSize - validate part size and multipart threshold against the multipart upload API limits
+func ValidatePartSize() error {
+	if s := configString(ConfigKeyMultipartThreshold); s != "" {
+		threshold, err := util.ParseByteSize(s)
+		if err != nil {
+			return err
+		}
+		if threshold < MinPartSize || threshold > Size50MB {
+			return fmt.Errorf("multipart threshold must be between %dMiB and %dMiB", MinPartSize>>20, Size50MB>>20)
+		}
+	}
+
+	partSize := PartSize()
+	if partSize == "" || partSize == PartSizeAuto {
+		return nil
+	}
+
+	size, err := util.ParseByteSize(partSize)
+	if err != nil {
+		return fmt.Errorf("part size must be %s or a size: %v", PartSizeAuto, err)
+	}
+	if size < MinPartSize || size > MaxPartSize {
+		return fmt.Errorf("part size must be between %dMiB and %dMiB", MinPartSize>>20, MaxPartSize>>20)
+	}
+	return nil
+}
+
+// SegmentSize - return the size of the byte ranges a large file is downloaded in
+func SegmentSize() int64 {
+	size, err := util.ParseByteSize(configString(ConfigKeySegmentSize))
+	if err != nil || size == 0 {
+		return Size50MB
+	}
+	return size
 }
 
-// SetUploadSessionID - Store upload session id in the configuration
-func SetUploadSessionID(sessionID string) {
-	viper.Set(ConfigUploadSessionID, sessionID)
+// SegmentThreshold - return the file size from which a file is downloaded in concurrent byte ranges
+func SegmentThreshold() int64 {
+	threshold, err := util.ParseByteSize(configString(ConfigKeySegmentThreshold))
+	if err != nil || threshold == 0 {
+		return 2 * Size50MB
+	}
+	return threshold
+}
+
+// ValidateSegmentSize - validate segment size and threshold of ranged downloads
+func ValidateSegmentSize() error {
+	if s := configString(ConfigKeySegmentSize); s != "" {
+		size, err := util.ParseByteSize(s)
+		if err != nil {
+			return err
+		}
+		if size < Size1MB {
+			return fmt.Errorf("segment size must be at least 1MiB")
+		}
+	}
+
+	if s := configString(ConfigKeySegmentThreshold); s != "" {
+		if _, err := util.ParseByteSize(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheTTL - return how long a remote listing is cached on disk, 0 when the cache is disabled
+func CacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(configString(ConfigKeyCacheTTL))
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// ValidateCacheTTL - validate the duration remote listings are cached
+func ValidateCacheTTL() error {
+	s := configString(ConfigKeyCacheTTL)
+	if s == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("cache ttl must be a duration, e.g. 10m or 1h: %v", err)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("cache ttl must not be negative")
+	}
+	return nil
+}
+
+// CacheDir - return the folder of the cached remote listings, next to the configuration file
+func CacheDir() string {
+	return filepath.Join(filepath.Dir(cfgFile), ".vvfst-cache")
+}
+
+// UploadSessions - return the journal of multipart upload sessions started from this computer
+func UploadSessions() []*model.UploadJournalEntry {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return uploadSessions()
+}
+
+func uploadSessions() []*model.UploadJournalEntry {
+	var entries []*model.UploadJournalEntry
+	if err := viper.UnmarshalKey(ConfigUploadSessions, &entries); err != nil {
+		vlog.Errorf("Error reading upload sessions: %v", err)
+	}
+	return entries
+}
+
+// UploadSession - return the journal entry of the multipart upload session for the remote file
+func UploadSession(remotePath string) *model.UploadJournalEntry {
+	for _, entry := range UploadSessions() {
+		if entry.RemotePath == remotePath {
+			return entry
+		}
+	}
+	return nil
+}
+
+// SaveUploadSession - add or replace the journal entry of the remote file and update the configuration
+func SaveUploadSession(entry *model.UploadJournalEntry) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	entries := []*model.UploadJournalEntry{entry}
+	for _, e := range uploadSessions() {
+		if e.RemotePath != entry.RemotePath {
+			entries = append(entries, e)
+		}
+	}
+	viper.Set(ConfigUploadSessions, entries)
+	writeConfig()
+}
+
+// RemoveUploadSession - remove the journal entry of the remote file and update the configuration
+func RemoveUploadSession(remotePath string) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	var entries []*model.UploadJournalEntry
+	found := false
+	for _, e := range uploadSessions() {
+		if e.RemotePath == remotePath {
+			found = true
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if found {
+		viper.Set(ConfigUploadSessions, entries)
+		writeConfig()
+	}
 }
 
 // SetAuthResult - Save auth result in the configuration
@@ -91,11 +310,17 @@ func SetAuthResult(result *model.AuthResult) {
 		"vault_id":   result.VaultID,
 		"user_id":    result.UserID,
 	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
 	viper.Set(ConfigAuthResult, authResult)
 }
 
 // AuthResult - return auth result from configuration
 func AuthResult() *model.AuthResult {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
 	if !viper.IsSet(ConfigAuthResult) {
 		return nil
 	}
@@ -115,42 +340,58 @@ func AuthResult() *model.AuthResult {
 	return authResult
 }
 
-var activeJobMutex = &sync.Mutex{}
+// configMutex - guards the configuration updated from concurrent uploads and jobs, every read of the
+// configuration takes the read lock since viper is not safe for concurrent use
+var configMutex = &sync.RWMutex{}
 
 // UpdateActiveJob - update list of active jobs in the configuration
 func UpdateActiveJob(jobID, status string) {
-	activeJobMutex.Lock()
-	defer activeJobMutex.Unlock()
+	configMutex.Lock()
+	defer configMutex.Unlock()
 
-	jobIDMap := ActiveJobs()
+	jobIDMap := activeJobs()
 	val, ok := jobIDMap[jobID]
 	if !ok || val != status {
 		jobIDMap[jobID] = status
 		viper.Set(ConfigActiveJobIDs, jobIDMap)
-		UpdateConfig()
+		writeConfig()
 	}
 }
 
 // RemoveActiveJob - remove and update list of active jobs in the configuration
 func RemoveActiveJob(jobID string) {
-	activeJobMutex.Lock()
-	defer activeJobMutex.Unlock()
+	configMutex.Lock()
+	defer configMutex.Unlock()
 
-	jobIDMap := ActiveJobs()
+	jobIDMap := activeJobs()
 	if _, ok := jobIDMap[jobID]; ok {
 		delete(jobIDMap, jobID)
 		viper.Set(ConfigActiveJobIDs, jobIDMap)
 	}
-	UpdateConfig()
+	writeConfig()
 }
 
 // ActiveJobs - return list of active jobs
 func ActiveJobs() map[string]string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+
+	return activeJobs()
+}
+
+func activeJobs() map[string]string {
 	return viper.GetStringMapString(ConfigActiveJobIDs)
 }
 
 // UpdateConfig - update configuration
 func UpdateConfig() {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	writeConfig()
+}
+
+func writeConfig() {
 	if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
 		if _, err := os.Create(cfgFile); err != nil {
 			vlog.Info("Config not exists, creating it")
@@ -165,6 +406,9 @@ func UpdateConfig() {
 
 // ResetAuthResult - clear authentication information
 func ResetAuthResult() {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
 	viper.Set(ConfigAuthResult, "")
 }
 
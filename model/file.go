@@ -88,6 +88,20 @@ type UploadSession struct {
 	CreatedDate        *time.Time `json:"created_date"`
 	ExpirationDate     *time.Time `json:"expiration_date"`
 	LastUploadedDate   *time.Time `json:"last_uploaded_date"`
+	// PartRetries - parts uploaded again after a failure during this run, not part of the API
+	PartRetries int `json:"-"`
+}
+
+// UploadJournalEntry - local record of the multipart upload session of a single file, the
+// fingerprint (size and modified time) tells whether the local file changed since the session began
+type UploadJournalEntry struct {
+	RemotePath   string `mapstructure:"remote_path" yaml:"remote_path"`
+	SessionID    string `mapstructure:"session_id" yaml:"session_id"`
+	LocalPath    string `mapstructure:"local_path" yaml:"local_path"`
+	Size         int64  `mapstructure:"size" yaml:"size"`
+	ModTime      int64  `mapstructure:"mod_time" yaml:"mod_time"`
+	PartsDone    int    `mapstructure:"parts_done" yaml:"parts_done"`
+	UploadedSize int64  `mapstructure:"uploaded_size" yaml:"uploaded_size"`
 }
 
 type UploadSessionsRestResult struct {
@@ -127,11 +141,28 @@ type JobRestResult struct {
 }
 
 // DownloadItem - Download from RemotePath or Remote Href, either one should be available.
+// Threads is the number of concurrent byte ranges of a large file, the modified time of the
+// downloaded file is set to ModifiedDate when it is known and Backup keeps an existing local file.
 type DownloadItem struct {
-	RemoteHref string
-	RemotePath string
-	Size       int64
-	LocalPath  string
+	RemoteHref   string
+	RemotePath   string
+	Size         int64
+	MD5          string
+	ModifiedDate *time.Time
+	LocalPath    string
+	Threads      int
+	Backup       bool
+}
+
+// PartialDownload - sidecar of a partially downloaded file, the download resumes only when the
+// remote item still has the same size and MD5.  A file downloaded in byte ranges records the
+// segment size and the index of every segment written.
+type PartialDownload struct {
+	RemotePath   string `json:"remote_path"`
+	Size         int64  `json:"size"`
+	MD5          string `json:"md5"`
+	SegmentSize  int64  `json:"segment_size,omitempty"`
+	SegmentsDone []int  `json:"segments_done,omitempty"`
 }
 
 type UploadItem struct {
@@ -155,3 +186,46 @@ type JobStatusRestResult struct {
 	RestResult
 	Data *JobStatusData
 }
+
+// TransferStatus - final status of a file upload or download
+type TransferStatus string
+
+const (
+	TransferSucceeded TransferStatus = "succeeded"
+	TransferFailed    TransferStatus = "failed"
+	TransferSkipped   TransferStatus = "skipped"
+)
+
+// TransferMethod - how the file content is transferred
+type TransferMethod string
+
+const (
+	TransferSimple    TransferMethod = "simple"
+	TransferMultipart TransferMethod = "multipart"
+	TransferRanged    TransferMethod = "ranged"
+)
+
+// VerifyStatus - outcome of comparing a downloaded file with the size and MD5 of the remote item
+type VerifyStatus string
+
+const (
+	VerifyPassed   VerifyStatus = "verified"
+	VerifyMismatch VerifyStatus = "mismatch"
+	VerifySkipped  VerifyStatus = "unverified"
+)
+
+// TransferResult - outcome of a single file upload or download
+type TransferResult struct {
+	LocalPath    string
+	RemotePath   string
+	Size         int64
+	MD5          string
+	Method       TransferMethod
+	Attempts     int
+	StartTime    time.Time
+	Duration     time.Duration
+	Status       TransferStatus
+	Verification VerifyStatus
+	ErrorType    string
+	Err          error
+}
@@ -18,14 +18,25 @@ package net
 import (
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
 	"github.com/veeva/vvfst/config"
 	"github.com/veeva/vvfst/model"
 	"github.com/veeva/vvfst/vlog"
+	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	ErrorTypeConnection  = "CONNECTION_ERROR"
+	ErrorTypeLocalIO     = "LOCAL_IO_ERROR"
+	ErrorTypeChecksum    = "CHECKSUM_MISMATCH"
+	ErrorTypeUnknown     = "UNKNOWN_ERROR"
+	ErrorTypeInvalidData = "INVALID_DATA"
+)
+
 var (
 	restClient *RestClient
 	once       sync.Once
@@ -84,6 +95,59 @@ func FormatRestResultError(msg string, resp *model.RestResultError) string {
 	return fmt.Sprintf("%s - [%s]: %s ", msg, resp.Type, resp.Message)
 }
 
+// RestError - error returned by the REST API, keeps the error type for reporting
+type RestError struct {
+	Type    string
+	Message string
+}
+
+func (e *RestError) Error() string {
+	return e.Message
+}
+
+func NewRestError(msg string, resp *model.RestResultError) error {
+	return &RestError{Type: resp.Type, Message: FormatRestResultError(msg, resp)}
+}
+
+// ChecksumError - downloaded content does not match the size or MD5 of the remote item
+type ChecksumError struct {
+	Path    string
+	Message string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s - [%s]: %s", e.Path, ErrorTypeChecksum, e.Message)
+}
+
+// ErrorType - return the REST API error type or the category of the failure
+func ErrorType(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var restErr *RestError
+	if errors.As(err, &restErr) {
+		return restErr.Type
+	}
+
+	var checksumErr *ChecksumError
+	if errors.As(err, &checksumErr) {
+		return ErrorTypeChecksum
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return ErrorTypeConnection
+	}
+
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return ErrorTypeLocalIO
+	}
+
+	return ErrorTypeUnknown
+}
+
 func IsSessionExpired(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "INVALID_SESSION_ID")
 }
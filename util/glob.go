@@ -0,0 +1,137 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package util
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// MatchGlob - Return true if the '/' separated relative path matches the glob pattern.
+// A pattern without '/' matches the last element at any level, '**' matches any number of folders.
+func MatchGlob(pattern, relPath string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	relPath = strings.Trim(relPath, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(relPath))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// IgnoreRule - single pattern line of an ignore file
+type IgnoreRule struct {
+	Pattern  string
+	Negate   bool
+	DirOnly  bool
+	Anchored bool
+}
+
+// IgnoreRules - ordered list of ignore rules, the last matching rule wins
+type IgnoreRules []*IgnoreRule
+
+// ParseIgnoreFile - Read ignore rules from the file, a missing file returns no rules
+func ParseIgnoreFile(filename string) (IgnoreRules, error) {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseIgnore(f)
+}
+
+// ParseIgnore - Read ignore rules with the gitignore syntax, one pattern per line
+func ParseIgnore(r io.Reader) (IgnoreRules, error) {
+	var rules IgnoreRules
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := &IgnoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.Negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // escaped leading '#' or '!'
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.DirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		// a separator at the beginning or in the middle anchors the pattern to the root folder
+		rule.Anchored = strings.Contains(line, "/")
+		rule.Pattern = strings.TrimPrefix(line, "/")
+		if rule.Pattern == "" {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// Ignored - Return true if the relative path is ignored by the rules
+func (rules IgnoreRules) Ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.DirOnly && !isDir {
+			continue
+		}
+
+		pattern := rule.Pattern
+		if !rule.Anchored {
+			pattern = "**/" + pattern
+		}
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(strings.Trim(relPath, "/"), "/")) {
+			ignored = !rule.Negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.xml", "a.xml", true},
+		{"*.xml", "inbox/2020/a.xml", true},
+		{"*.xml", "inbox/a.csv", false},
+		{".DS_Store", "a/b/.DS_Store", true},
+		{"inbox/*.xml", "inbox/a.xml", true},
+		{"inbox/*.xml", "inbox/2020/a.xml", false},
+		{"inbox/**/*.xml", "inbox/a.xml", true},
+		{"inbox/**/*.xml", "inbox/2020/10/a.xml", true},
+		{"**/tmp", "a/b/tmp", true},
+		{"/inbox/*.xml", "inbox/a.xml", true},
+	}
+
+	for _, test := range tests {
+		if got := MatchGlob(test.pattern, test.path); got != test.want {
+			t.Errorf("MatchGlob(%q, %q) = %t, want %t", test.pattern, test.path, got, test.want)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := ParseIgnore(strings.NewReader(`
+# comment
+.DS_Store
+*.tmp
+!keep.tmp
+build/
+/root.txt
+docs/**/*.bak
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".DS_Store", false, true},
+		{"a/b/.DS_Store", false, true},
+		{"a/x.tmp", false, true},
+		{"a/keep.tmp", false, false},
+		{"build", true, true},
+		{"src/build", true, true},
+		{"build", false, false},
+		{"root.txt", false, true},
+		{"a/root.txt", false, false},
+		{"docs/a/b/c.bak", false, true},
+		{"a/docs/c.bak", false, false},
+		{"a/readme.md", false, false},
+	}
+
+	for _, test := range tests {
+		if got := rules.Ignored(test.path, test.isDir); got != test.want {
+			t.Errorf("Ignored(%q, %t) = %t, want %t", test.path, test.isDir, got, test.want)
+		}
+	}
+}
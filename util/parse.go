@@ -0,0 +1,89 @@
+/*
+This code serves as an example and is not meant for production use.
+
+Copyright 2020 Veeva Systems Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+either express or implied. See the License for the specific language governing permissions
+and limitations under the License.
+*/
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// byteUnits - every unit uses 1024 as the sizes of the API, so 5MB is read as 5MiB
+var byteUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1024,
+	"MB":  1024 * 1024,
+	"GB":  1024 * 1024 * 1024,
+	"TB":  1024 * 1024 * 1024 * 1024,
+	"KIB": 1024,
+	"MIB": 1024 * 1024,
+	"GIB": 1024 * 1024 * 1024,
+	"TIB": 1024 * 1024 * 1024 * 1024,
+}
+
+// ParseByteSize - Parse size such as 1024, 10KiB, 5MB or 5MiB into bytes, every unit uses 1024 so 5MB is 5MiB
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseTimeOrAge - Parse a timestamp (RFC3339 or yyyy-mm-dd) or an age such as 36h, 90m or 7d relative to now
+func ParseTimeOrAge(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err == nil {
+			return now.Add(-time.Duration(days * float64(24*time.Hour))), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time or duration: %q", s)
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":  1024,
+		"10kB":  10 * 1024,
+		"10KiB": 10 * 1024,
+		"5MB":   5 * 1024 * 1024,
+		"5MiB":  5 * 1024 * 1024,
+		"50mb":  50 * 1024 * 1024,
+		"1.5GB": 1536 * 1024 * 1024,
+	}
+
+	for s, want := range tests {
+		got, err := ParseByteSize(s)
+		if err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "MB", "5XB", "-1"} {
+		if _, err := ParseByteSize(s); err == nil {
+			t.Errorf("ParseByteSize(%q) expected error", s)
+		}
+	}
+}
+
+func TestParseTimeOrAge(t *testing.T) {
+	now := time.Date(2020, 10, 20, 12, 0, 0, 0, time.Local)
+	tests := map[string]time.Time{
+		"36h":                  now.Add(-36 * time.Hour),
+		"90m":                  now.Add(-90 * time.Minute),
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"1.5d":                 now.Add(-36 * time.Hour),
+		" 2h ":                 now.Add(-2 * time.Hour),
+		"2020-10-01":           time.Date(2020, 10, 1, 0, 0, 0, 0, time.Local),
+		"2020-10-01 08:30:00":  time.Date(2020, 10, 1, 8, 30, 0, 0, time.Local),
+		"2020-10-01T08:30:00Z": time.Date(2020, 10, 1, 8, 30, 0, 0, time.UTC),
+	}
+
+	for s, want := range tests {
+		got, err := ParseTimeOrAge(s, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTimeOrAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "d", "yesterday", "2020-13-01", "7w"} {
+		if _, err := ParseTimeOrAge(s, now); err == nil {
+			t.Errorf("ParseTimeOrAge(%q) expected error", s)
+		}
+	}
+}
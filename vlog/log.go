@@ -19,22 +19,33 @@ import (
 	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"io"
 	"os"
 	"strings"
 )
 
 var logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
+var logNoColor bool
+
 // InitLog - initialize the logging
 func InitLog(noColor bool) {
-	output := zerolog.ConsoleWriter{
-		Out:         os.Stdout,
+	logNoColor = noColor
+	logger = zerolog.New(consoleWriter(os.Stdout)).With().Timestamp().Logger().Level(zerolog.InfoLevel)
+}
+
+// SetOutput - write the log to w instead of stdout, used when stdout carries the content of a file
+func SetOutput(w io.Writer) {
+	logger = logger.Output(consoleWriter(w))
+}
+
+func consoleWriter(w io.Writer) zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{
+		Out:         w,
 		TimeFormat:  zerolog.TimeFormatUnix,
-		FormatLevel: consoleDefaultFormatLevel(noColor),
-		NoColor:     noColor,
+		FormatLevel: consoleDefaultFormatLevel(logNoColor),
+		NoColor:     logNoColor,
 	}
-
-	logger = zerolog.New(output).With().Timestamp().Logger().Level(zerolog.InfoLevel)
 }
 
 // Trace - log message in trace level